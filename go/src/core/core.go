@@ -6,6 +6,8 @@ import (
 	"io/ioutil"
 	"strings"
 	"time"
+	"unicode"
+	"unicode/utf8"
 )
 
 import (
@@ -61,20 +63,462 @@ func slurp(a []MalType) (MalType, error) {
 	return string(b), nil
 }
 
+// (read-file f) is a list of the forms in file f, whose metadata has
+// their locations in f
+func read_file(a []MalType) (MalType, error) {
+	file, e := str_arg("read-file", a[0])
+	if e != nil {
+		return nil, e
+	}
+	b, e := ioutil.ReadFile(file)
+	if e != nil {
+		return nil, e
+	}
+	forms, e := reader.Read_file(string(b), file)
+	if e != nil {
+		return nil, e
+	}
+	return List{Val: forms}, nil
+}
+
+// The string library is in the string namespace, as (string/join ", " xs),
+// except for subs, char and code-point. Indices count characters (code
+// points), not bytes.
+
+func str_arg(name string, obj MalType) (string, error) {
+	s, ok := obj.(string)
+	if !ok {
+		return "", errors.New(name + " called with non-string")
+	}
+	return s, nil
+}
+
+func index_arg(name string, obj MalType) (int, error) {
+	i, ok := obj.(int)
+	if !ok {
+		return 0, errors.New(name + " called with non-integer index")
+	}
+	return i, nil
+}
+
+// (subs s start) or (subs s start end)
+func subs(a []MalType) (MalType, error) {
+	s, e := str_arg("subs", a[0])
+	if e != nil {
+		return nil, e
+	}
+	r := []rune(s)
+	start, e := index_arg("subs", a[1])
+	if e != nil {
+		return nil, e
+	}
+	end := len(r)
+	if len(a) == 3 {
+		if end, e = index_arg("subs", a[2]); e != nil {
+			return nil, e
+		}
+	}
+	if start < 0 || end < start || end > len(r) {
+		return nil, errors.New("subs: index out of range")
+	}
+	return string(r[start:end]), nil
+}
+
+// (string/split s sep) or (string/split s sep limit), which splits s into
+// at most limit strings. sep is a string or a regex, and an empty sep
+// splits s into characters.
+func split(a []MalType) (MalType, error) {
+	s, e := str_arg("split", a[0])
+	if e != nil {
+		return nil, e
+	}
+	limit := -1
+	if len(a) == 3 {
+		var ok bool
+		if limit, ok = a[2].(int); !ok || limit <= 0 {
+			return nil, errors.New("split called with non-positive limit")
+		}
+	}
+	var parts []string
+	if re, ok := a[1].(Regex); ok {
+		parts = re.Split(s, limit)
+	} else {
+		sep, e := str_arg("split", a[1])
+		if e != nil {
+			return nil, e
+		}
+		parts = strings.SplitN(s, sep, limit)
+	}
+	new_slc := []MalType{}
+	for _, part := range parts {
+		new_slc = append(new_slc, part)
+	}
+	return NewVector(new_slc...), nil
+}
+
+// (string/join coll) or (string/join sep coll) joins the elements of coll
+// as str prints them
+func join(a []MalType) (MalType, error) {
+	sep := ""
+	if len(a) == 2 {
+		var e error
+		if sep, e = str_arg("join", a[0]); e != nil {
+			return nil, e
+		}
+	}
+	coll, e := seq(a[len(a)-1:])
+	if e != nil {
+		return nil, errors.New("join called with non-collection")
+	}
+	if coll == nil {
+		return "", nil
+	}
+	return printer.Pr_list(coll.(List).Slice(), false, "", "", sep), nil
+}
+
+// string_fn makes a string library function of one string argument
+func string_fn(name string, f func(string) string) func([]MalType) (MalType, error) {
+	return func(a []MalType) (MalType, error) {
+		s, e := str_arg(name, a[0])
+		if e != nil {
+			return nil, e
+		}
+		return f(s), nil
+	}
+}
+
+// string_test makes a string library predicate of two string arguments
+func string_test(name string, f func(string, string) bool) func([]MalType) (MalType, error) {
+	return func(a []MalType) (MalType, error) {
+		s, e := str_arg(name, a[0])
+		if e != nil {
+			return nil, e
+		}
+		t, e := str_arg(name, a[1])
+		if e != nil {
+			return nil, e
+		}
+		return f(s, t), nil
+	}
+}
+
+// (string/index-of s value) or (string/index-of s value from) is the index
+// of the first value in s at or after from, or nil if there is none
+func index_of(a []MalType) (MalType, error) {
+	s, e := str_arg("index-of", a[0])
+	if e != nil {
+		return nil, e
+	}
+	value, e := str_arg("index-of", a[1])
+	if e != nil {
+		return nil, e
+	}
+	from := 0
+	if len(a) == 3 {
+		if from, e = index_arg("index-of", a[2]); e != nil {
+			return nil, e
+		}
+	}
+	r := []rune(s)
+	if from < 0 || from > len(r) {
+		return nil, errors.New("index-of: index out of range")
+	}
+	rest := string(r[from:])
+	i := strings.Index(rest, value)
+	if i < 0 {
+		return nil, nil
+	}
+	return from + utf8.RuneCountInString(rest[:i]), nil
+}
+
+// (string/replace s match replacement) replaces every match in s. When
+// match is a regex, replacement can refer to its groups as $1, or be a
+// function of each match, as re-find returns it.
+func replace(a []MalType) (MalType, error) {
+	s, e := str_arg("replace", a[0])
+	if e != nil {
+		return nil, e
+	}
+	re, ok := a[1].(Regex)
+	if !ok {
+		match, e := str_arg("replace", a[1])
+		if e != nil {
+			return nil, e
+		}
+		replacement, e := str_arg("replace", a[2])
+		if e != nil {
+			return nil, e
+		}
+		return strings.Replace(s, match, replacement, -1), nil
+	}
+	if replacement, ok := a[2].(string); ok {
+		return re.ReplaceAllString(s, replacement), nil
+	}
+	if is_fn, _ := fn_q(a[2:]); !True_Q(is_fn) {
+		return nil, errors.New("replace called with non-string replacement")
+	}
+	var b strings.Builder
+	last := 0
+	for _, loc := range re.FindAllStringSubmatchIndex(s, -1) {
+		res, e := Apply(a[2], []MalType{re_groups(s, loc)})
+		if e != nil {
+			return nil, e
+		}
+		b.WriteString(s[last:loc[0]])
+		b.WriteString(printer.Pr_str(res, false))
+		last = loc[1]
+	}
+	b.WriteString(s[last:])
+	return b.String(), nil
+}
+
+// (char 97) is "a", the string of the one character with that code point
+func char(a []MalType) (MalType, error) {
+	i, ok := a[0].(int)
+	if !ok || i < 0 || i > unicode.MaxRune || !utf8.ValidRune(rune(i)) {
+		return nil, errors.New("char called with invalid code point")
+	}
+	return string(rune(i)), nil
+}
+
+// (code-point "a") is 97
+func code_point(a []MalType) (MalType, error) {
+	s, e := str_arg("code-point", a[0])
+	if e != nil {
+		return nil, e
+	}
+	r, size := utf8.DecodeRuneInString(s)
+	if size == 0 || size != len(s) || r == utf8.RuneError {
+		return nil, errors.New("code-point called with string not of one character")
+	}
+	return int(r), nil
+}
+
+// Regex functions
+
+func regex_arg(name string, obj MalType) (Regex, error) {
+	re, ok := obj.(Regex)
+	if !ok {
+		return Regex{}, errors.New(name + " called with non-regex")
+	}
+	return re, nil
+}
+
+// re_groups is the match of a regex at loc in s, which is the matched
+// string, or a vector of it and the groups if the regex has any. A group
+// which didn't match is nil. There is no match if loc is nil.
+func re_groups(s string, loc []int) MalType {
+	if loc == nil {
+		return nil
+	}
+	if len(loc) == 2 {
+		return s[loc[0]:loc[1]]
+	}
+	groups := make([]MalType, 0, len(loc)/2)
+	for i := 0; i < len(loc); i += 2 {
+		if loc[i] < 0 {
+			groups = append(groups, nil)
+		} else {
+			groups = append(groups, s[loc[i]:loc[i+1]])
+		}
+	}
+	return NewVector(groups...)
+}
+
+func re_pattern(a []MalType) (MalType, error) {
+	if Regex_Q(a[0]) {
+		return a[0], nil
+	}
+	pattern, e := str_arg("re-pattern", a[0])
+	if e != nil {
+		return nil, e
+	}
+	return NewRegex(pattern)
+}
+
+// (re-find re s) is the first match of re in s
+func re_find(a []MalType) (MalType, error) {
+	re, e := regex_arg("re-find", a[0])
+	if e != nil {
+		return nil, e
+	}
+	s, e := str_arg("re-find", a[1])
+	if e != nil {
+		return nil, e
+	}
+	return re_groups(s, re.FindStringSubmatchIndex(s)), nil
+}
+
+// (re-matches re s) is the match of re if it matches the whole of s
+func re_matches(a []MalType) (MalType, error) {
+	re, e := regex_arg("re-matches", a[0])
+	if e != nil {
+		return nil, e
+	}
+	s, e := str_arg("re-matches", a[1])
+	if e != nil {
+		return nil, e
+	}
+	return re_groups(s, re.FindWholeStringSubmatchIndex(s)), nil
+}
+
+// (re-seq re s) is a list of the matches of re in s, or nil
+func re_seq(a []MalType) (MalType, error) {
+	re, e := regex_arg("re-seq", a[0])
+	if e != nil {
+		return nil, e
+	}
+	s, e := str_arg("re-seq", a[1])
+	if e != nil {
+		return nil, e
+	}
+	locs := re.FindAllStringSubmatchIndex(s, -1)
+	if len(locs) == 0 {
+		return nil, nil
+	}
+	new_slc := []MalType{}
+	for _, loc := range locs {
+		new_slc = append(new_slc, re_groups(s, loc))
+	}
+	return List{Val: new_slc}, nil
+}
+
+// Keyword functions
+
+// (keyword "name"), (keyword "ns/name") or (keyword "ns" "name")
+func keyword(a []MalType) (MalType, error) {
+	switch len(a) {
+	case 1:
+		if Keyword_Q(a[0]) {
+			return a[0], nil
+		}
+		if s, ok := a[0].(string); ok {
+			return NewKeyword(s)
+		}
+	case 2:
+		ns, ok1 := a[0].(string)
+		s, ok2 := a[1].(string)
+		if ok1 && ok2 {
+			if ns == "" {
+				return NewKeyword(s)
+			}
+			return NewKeyword(ns + "/" + s)
+		}
+	default:
+		return nil, fmt.Errorf("wrong number of arguments (%d instead of 1 or 2)", len(a))
+	}
+	return nil, errors.New("keyword called with non-string")
+}
+
+func name(a []MalType) (MalType, error) {
+	switch obj := a[0].(type) {
+	case Keyword:
+		return obj.Name(), nil
+	case Symbol:
+		return obj.Name(), nil
+	case string:
+		return obj, nil
+	}
+	return nil, errors.New("name called on non-keyword")
+}
+
+// namespace is nil for a keyword or symbol without a namespace
+func namespace(a []MalType) (MalType, error) {
+	var ns string
+	switch obj := a[0].(type) {
+	case Keyword:
+		ns = obj.Namespace()
+	case Symbol:
+		ns = obj.Namespace()
+	default:
+		return nil, errors.New("namespace called on non-keyword")
+	}
+	if ns == "" {
+		return nil, nil
+	}
+	return ns, nil
+}
+
 // Number functions
-func time_ms(a []MalType) (MalType, error) {
-	return int(time.Now().UnixNano() / int64(time.Millisecond)), nil
+func check_numbers(name string, a []MalType) error {
+	for _, n := range a {
+		if !Number_Q(n) {
+			return fmt.Errorf("%s called with non-number %s", name, printer.Pr_str(n, true))
+		}
+	}
+	return nil
 }
 
-// Hash Map functions
-func copy_hash_map(hm HashMap) HashMap {
-	new_hm := HashMap{map[string]MalType{}, nil}
-	for k, v := range hm.Val {
-		new_hm.Val[k] = v
+// fold applies op from left to right, starting with init if given
+func fold(name string, op func(MalType, MalType) (MalType, error), init MalType) func([]MalType) (MalType, error) {
+	return func(a []MalType) (MalType, error) {
+		if e := check_numbers(name, a); e != nil {
+			return nil, e
+		}
+		if init != nil {
+			a = append([]MalType{init}, a...)
+		}
+		res := a[0]
+		for _, n := range a[1:] {
+			var e error
+			if res, e = op(res, n); e != nil {
+				return nil, e
+			}
+		}
+		return res, nil
+	}
+}
+
+// (- x) negates x, and (- x y ...) subtracts the rest from x
+func sub(a []MalType) (MalType, error) {
+	if len(a) == 1 {
+		if e := check_numbers("-", a); e != nil {
+			return nil, e
+		}
+		return Neg(a[0])
+	}
+	return fold("-", Sub, nil)(a)
+}
+
+// (/ x) is the reciprocal of x, and (/ x y ...) divides x by the rest
+func div(a []MalType) (MalType, error) {
+	op := func(x MalType, y MalType) (MalType, error) {
+		q, e := Div(x, y)
+		if e == ErrDivisionByZero {
+			return nil, fmt.Errorf("/ called with zero divisor %s", printer.Pr_str(y, true))
+		}
+		return q, e
+	}
+	if len(a) == 1 {
+		return fold("/", op, 1)(a)
+	}
+	return fold("/", op, nil)(a)
+}
+
+// compare is true if every adjacent pair of arguments is ordered by f
+func compare(name string, f func(int) bool) func([]MalType) (MalType, error) {
+	return func(a []MalType) (MalType, error) {
+		if e := check_numbers(name, a); e != nil {
+			return nil, e
+		}
+		for i := 1; i < len(a); i++ {
+			c, e := NumCmp(name, a[i-1], a[i])
+			if e != nil {
+				return nil, e
+			}
+			if !f(c) {
+				return false, nil
+			}
+		}
+		return true, nil
 	}
-	return new_hm
 }
 
+func time_ms(a []MalType) (MalType, error) {
+	return int(time.Now().UnixNano() / int64(time.Millisecond)), nil
+}
+
+// Hash Map functions
 func assoc(a []MalType) (MalType, error) {
 	if len(a) < 3 {
 		return nil, errors.New("assoc requires at least 3 arguments")
@@ -82,16 +526,31 @@ func assoc(a []MalType) (MalType, error) {
 	if len(a)%2 != 1 {
 		return nil, errors.New("assoc requires odd number of arguments")
 	}
+	if vec, ok := a[0].(Vector); ok {
+		for i := 1; i < len(a); i += 2 {
+			idx, ok := a[i].(int)
+			if !ok || idx < 0 || idx > vec.Count() {
+				return nil, errors.New("assoc: index out of range")
+			}
+			vec = vec.Assoc(idx, a[i+1])
+		}
+		return vec, nil
+	}
+	if sm, ok := a[0].(SortedMap); ok {
+		for i := 1; i < len(a); i += 2 {
+			var e error
+			if sm, e = sm.Assoc(a[i], a[i+1]); e != nil {
+				return nil, e
+			}
+		}
+		return sm, nil
+	}
 	if !HashMap_Q(a[0]) {
 		return nil, errors.New("assoc called on non-hash map")
 	}
-	new_hm := copy_hash_map(a[0].(HashMap))
+	new_hm := a[0].(HashMap)
 	for i := 1; i < len(a); i += 2 {
-		key := a[i]
-		if !String_Q(key) {
-			return nil, errors.New("assoc called with non-string key")
-		}
-		new_hm.Val[key.(string)] = a[i+1]
+		new_hm = new_hm.Assoc(a[i], a[i+1])
 	}
 	return new_hm, nil
 }
@@ -100,105 +559,395 @@ func dissoc(a []MalType) (MalType, error) {
 	if len(a) < 2 {
 		return nil, errors.New("dissoc requires at least 3 arguments")
 	}
+	if sm, ok := a[0].(SortedMap); ok {
+		for i := 1; i < len(a); i += 1 {
+			var e error
+			if sm, e = sm.Dissoc(a[i]); e != nil {
+				return nil, e
+			}
+		}
+		return sm, nil
+	}
 	if !HashMap_Q(a[0]) {
 		return nil, errors.New("dissoc called on non-hash map")
 	}
-	new_hm := copy_hash_map(a[0].(HashMap))
+	new_hm := a[0].(HashMap)
 	for i := 1; i < len(a); i += 1 {
-		key := a[i]
-		if !String_Q(key) {
-			return nil, errors.New("dissoc called with non-string key")
-		}
-		delete(new_hm.Val, key.(string))
+		new_hm = new_hm.Dissoc(a[i])
 	}
 	return new_hm, nil
 }
 
-func get(a []MalType) (MalType, error) {
-	if Nil_Q(a[0]) {
-		return nil, nil
-	}
-	if !HashMap_Q(a[0]) {
-		return nil, errors.New("get called on non-hash map")
-	}
-	if !String_Q(a[1]) {
-		return nil, errors.New("get called with non-string key")
+// lookup finds key in a hash-map, sorted-map or sorted-set, in which the
+// value of an element is the element itself
+func lookup(name string, coll MalType, key MalType) (MalType, bool, error) {
+	switch tcoll := coll.(type) {
+	case nil:
+		return nil, false, nil
+	case HashMap:
+		v, ok := tcoll.Get(key)
+		return v, ok, nil
+	case SortedMap:
+		return tcoll.Get(key)
+	case SortedSet:
+		ok, e := tcoll.Contains(key)
+		if !ok {
+			return nil, false, e
+		}
+		return key, true, nil
+	case HashSet:
+		if !tcoll.Contains(key) {
+			return nil, false, nil
+		}
+		return key, true, nil
 	}
-	return a[0].(HashMap).Val[a[1].(string)], nil
+	return nil, false, errors.New(name + " called on non-hash map")
+}
+
+func get(a []MalType) (MalType, error) {
+	v, _, e := lookup("get", a[0], a[1])
+	return v, e
 }
 
 func contains_Q(hm MalType, key MalType) (MalType, error) {
-	if Nil_Q(hm) {
-		return false, nil
-	}
-	if !HashMap_Q(hm) {
-		return nil, errors.New("get called on non-hash map")
-	}
-	if !String_Q(key) {
-		return nil, errors.New("get called with non-string key")
+	_, ok, e := lookup("contains?", hm, key)
+	if e != nil {
+		return nil, e
 	}
-	_, ok := hm.(HashMap).Val[key.(string)]
 	return ok, nil
 }
 
+func entries(name string, hm MalType) ([]MapEntry, error) {
+	switch thm := hm.(type) {
+	case HashMap:
+		return thm.Entries(), nil
+	case SortedMap:
+		return thm.Entries(), nil
+	}
+	return nil, errors.New(name + " called on non-hash map")
+}
+
 func keys(a []MalType) (MalType, error) {
-	if !HashMap_Q(a[0]) {
-		return nil, errors.New("keys called on non-hash map")
+	ents, e := entries("keys", a[0])
+	if e != nil {
+		return nil, e
 	}
 	slc := []MalType{}
-	for k, _ := range a[0].(HashMap).Val {
-		slc = append(slc, k)
+	for _, ent := range ents {
+		slc = append(slc, ent.Key)
 	}
-	return List{slc, nil}, nil
+	return List{Val: slc}, nil
 }
 
 func vals(a []MalType) (MalType, error) {
-	if !HashMap_Q(a[0]) {
-		return nil, errors.New("keys called on non-hash map")
+	ents, e := entries("vals", a[0])
+	if e != nil {
+		return nil, e
 	}
 	slc := []MalType{}
-	for _, v := range a[0].(HashMap).Val {
-		slc = append(slc, v)
+	for _, ent := range ents {
+		slc = append(slc, ent.Val)
+	}
+	return List{Val: slc}, nil
+}
+
+// Set functions
+func elems(name string, set MalType) ([]MalType, error) {
+	switch tset := set.(type) {
+	case HashSet:
+		return tset.Slice(), nil
+	case SortedSet:
+		return tset.Slice(), nil
+	}
+	return nil, errors.New(name + " called on non-set")
+}
+
+func set_conj(set MalType, x MalType) (MalType, error) {
+	if ss, ok := set.(SortedSet); ok {
+		return ss.Conj(x)
+	}
+	return set.(HashSet).Conj(x), nil
+}
+
+func set_disj(set MalType, x MalType) (MalType, error) {
+	if ss, ok := set.(SortedSet); ok {
+		return ss.Disj(x)
+	}
+	return set.(HashSet).Disj(x), nil
+}
+
+func to_set(a []MalType) (MalType, error) {
+	switch coll := a[0].(type) {
+	case nil:
+		return HashSet{}, nil
+	case HashSet:
+		coll.Meta = nil
+		return coll, nil
+	case SortedSet:
+		return NewHashSet(coll.Slice()...), nil
+	case List, Vector:
+		slc, _ := GetSlice(coll)
+		return NewHashSet(slc...), nil
+	case HashMap, SortedMap:
+		// the elements are the [key value] entries
+		ents, _ := entries("set", coll)
+		hs := HashSet{}
+		for _, ent := range ents {
+			hs = hs.Conj(NewVector(ent.Key, ent.Val))
+		}
+		return hs, nil
+	}
+	return nil, errors.New("set called on non-collection")
+}
+
+func disj(a []MalType) (MalType, error) {
+	if !Set_Q(a[0]) {
+		return nil, errors.New("disj called on non-set")
+	}
+	set := a[0]
+	for _, x := range a[1:] {
+		var e error
+		if set, e = set_disj(set, x); e != nil {
+			return nil, e
+		}
+	}
+	return set, nil
+}
+
+// (union s1 s2 ...) is a set of the type of s1
+func union(a []MalType) (MalType, error) {
+	if len(a) == 0 {
+		return HashSet{}, nil
+	}
+	if _, e := elems("union", a[0]); e != nil {
+		return nil, e
+	}
+	set := a[0]
+	for _, s := range a[1:] {
+		slc, e := elems("union", s)
+		if e != nil {
+			return nil, e
+		}
+		for _, x := range slc {
+			if set, e = set_conj(set, x); e != nil {
+				return nil, e
+			}
+		}
+	}
+	return set, nil
+}
+
+// filter_set keeps the elements of a[0] which keep returns true for
+func filter_set(name string, a []MalType, keep func(MalType) (bool, error)) (MalType, error) {
+	slc, e := elems(name, a[0])
+	if e != nil {
+		return nil, e
+	}
+	set := a[0]
+	for _, x := range slc {
+		ok, e := keep(x)
+		if e == nil && !ok {
+			set, e = set_disj(set, x)
+		}
+		if e != nil {
+			return nil, e
+		}
+	}
+	return set, nil
+}
+
+func intersection(a []MalType) (MalType, error) {
+	return filter_set("intersection", a, func(x MalType) (bool, error) {
+		for _, s := range a[1:] {
+			_, ok, e := lookup("intersection", s, x)
+			if e != nil || !ok {
+				return false, e
+			}
+		}
+		return true, nil
+	})
+}
+
+func difference(a []MalType) (MalType, error) {
+	return filter_set("difference", a, func(x MalType) (bool, error) {
+		for _, s := range a[1:] {
+			_, ok, e := lookup("difference", s, x)
+			if e != nil || ok {
+				return false, e
+			}
+		}
+		return true, nil
+	})
+}
+
+func subset_Q(a []MalType) (MalType, error) {
+	slc, e := elems("subset?", a[0])
+	if e != nil {
+		return nil, e
+	}
+	if !Set_Q(a[1]) {
+		return nil, errors.New("subset? called on non-set")
+	}
+	for _, x := range slc {
+		if _, ok, e := lookup("subset?", a[1], x); e != nil || !ok {
+			return false, e
+		}
+	}
+	return true, nil
+}
+
+// (select pred set) is the set of the elements for which pred is true
+func do_select(a []MalType) (MalType, error) {
+	return filter_set("select", a[1:], func(x MalType) (bool, error) {
+		res, e := Apply(a[0], []MalType{x})
+		return res != nil && res != false, e
+	})
+}
+
+// Sorted collection functions
+func sorted_map(cmp Comparator, a []MalType) (MalType, error) {
+	if len(a)%2 == 1 {
+		return nil, errors.New("sorted-map called with odd number of arguments")
+	}
+	sm := NewSortedMap(cmp)
+	for i := 0; i < len(a); i += 2 {
+		var e error
+		if sm, e = sm.Assoc(a[i], a[i+1]); e != nil {
+			return nil, e
+		}
+	}
+	return sm, nil
+}
+
+func sorted_set(cmp Comparator, a []MalType) (MalType, error) {
+	ss := NewSortedSet(cmp)
+	for _, x := range a {
+		var e error
+		if ss, e = ss.Conj(x); e != nil {
+			return nil, e
+		}
+	}
+	return ss, nil
+}
+
+func do_compare(a []MalType) (MalType, error) {
+	c, e := Compare(a[0], a[1])
+	if e != nil {
+		return nil, e
+	}
+	return c, nil
+}
+
+func sorted_Q(obj MalType) bool {
+	return SortedMap_Q(obj) || SortedSet_Q(obj)
+}
+
+// subseq returns the entries of a sorted-map, or the elements of a
+// sorted-set, whose keys k pass (test (compare k key) 0) for each test
+// and key given, as (subseq sc < 3) or (subseq sc >= 1 < 3)
+func subseq(name string, reverse bool) func([]MalType) (MalType, error) {
+	return func(a []MalType) (MalType, error) {
+		if len(a) != 3 && len(a) != 5 {
+			return nil, fmt.Errorf("wrong number of arguments (%d instead of 3 or 5)", len(a))
+		}
+		var items, keys []MalType
+		var cmp func(MalType, MalType) (int, error)
+		switch sc := a[0].(type) {
+		case SortedMap:
+			for _, ent := range sc.Entries() {
+				items = append(items, NewVector(ent.Key, ent.Val))
+				keys = append(keys, ent.Key)
+			}
+			cmp = sc.Compare
+		case SortedSet:
+			items = sc.Slice()
+			keys = items
+			cmp = sc.Compare
+		default:
+			return nil, errors.New(name + " called on non-sorted collection")
+		}
+		res := []MalType{}
+		for i, k := range keys {
+			ok := true
+			for j := 1; ok && j < len(a); j += 2 {
+				c, e := cmp(k, a[j+1])
+				if e != nil {
+					return nil, e
+				}
+				pass, e := Apply(a[j], []MalType{c, 0})
+				if e != nil {
+					return nil, e
+				}
+				ok = True_Q(pass)
+			}
+			if ok {
+				res = append(res, items[i])
+			}
+		}
+		if len(res) == 0 {
+			return nil, nil
+		}
+		if reverse {
+			for i, j := 0, len(res)-1; i < j; i, j = i+1, j-1 {
+				res[i], res[j] = res[j], res[i]
+			}
+		}
+		return List{Val: res}, nil
 	}
-	return List{slc, nil}, nil
 }
 
 // Sequence functions
 
 func cons(a []MalType) (MalType, error) {
 	val := a[0]
-	lst, e := GetSlice(a[1])
+	if lst, ok := a[1].(List); ok {
+		return Cons(val, lst), nil
+	}
+	slc, e := GetSlice(a[1])
 	if e != nil {
 		return nil, e
 	}
-	return List{append([]MalType{val}, lst...), nil}, nil
+	return Cons(val, List{Val: slc}), nil
 }
 
 func concat(a []MalType) (MalType, error) {
 	if len(a) == 0 {
 		return List{}, nil
 	}
-	slc1, e := GetSlice(a[0])
-	if e != nil {
-		return nil, e
-	}
-	for i := 1; i < len(a); i += 1 {
+	// the slices of the arguments may be shared, so they are copied
+	slc1 := []MalType{}
+	for i := 0; i < len(a); i += 1 {
 		slc2, e := GetSlice(a[i])
 		if e != nil {
 			return nil, e
 		}
 		slc1 = append(slc1, slc2...)
 	}
-	return List{slc1, nil}, nil
+	return List{Val: slc1}, nil
 }
 
 func nth(a []MalType) (MalType, error) {
+	idx, ok := a[1].(int)
+	if !ok {
+		return nil, errors.New("nth called with non-integer index")
+	}
+	if vec, ok := a[0].(Vector); ok {
+		if idx < 0 || idx >= vec.Count() {
+			return nil, errors.New("nth: index out of range")
+		}
+		return vec.Nth(idx), nil
+	}
+	if lst, ok := a[0].(List); ok {
+		if idx < 0 || idx >= lst.Count() {
+			return nil, errors.New("nth: index out of range")
+		}
+		return lst.Nth(idx), nil
+	}
 	slc, e := GetSlice(a[0])
 	if e != nil {
 		return nil, e
 	}
-	idx := a[1].(int)
-	if idx < len(slc) {
+	if idx >= 0 && idx < len(slc) {
 		return slc[idx], nil
 	} else {
 		return nil, errors.New("nth: index out of range")
@@ -212,6 +961,15 @@ func first(a []MalType) (MalType, error) {
 	if a[0] == nil {
 		return nil, nil
 	}
+	if vec, ok := a[0].(Vector); ok {
+		if vec.Count() == 0 {
+			return nil, nil
+		}
+		return vec.Nth(0), nil
+	}
+	if lst, ok := a[0].(List); ok {
+		return lst.First(), nil
+	}
 	slc, e := GetSlice(a[0])
 	if e != nil {
 		return nil, e
@@ -226,6 +984,9 @@ func rest(a []MalType) (MalType, error) {
 	if a[0] == nil {
 		return List{}, nil
 	}
+	if lst, ok := a[0].(List); ok {
+		return lst.Rest(), nil
+	}
 	slc, e := GetSlice(a[0])
 	if e != nil {
 		return nil, e
@@ -233,15 +994,23 @@ func rest(a []MalType) (MalType, error) {
 	if len(slc) == 0 {
 		return List{}, nil
 	}
-	return List{slc[1:], nil}, nil
+	return List{Val: slc[1:]}, nil
 }
 
 func empty_Q(a []MalType) (MalType, error) {
 	switch obj := a[0].(type) {
 	case List:
-		return len(obj.Val) == 0, nil
+		return obj.Count() == 0, nil
 	case Vector:
-		return len(obj.Val) == 0, nil
+		return obj.Count() == 0, nil
+	case HashMap:
+		return obj.Count() == 0, nil
+	case SortedMap:
+		return obj.Count() == 0, nil
+	case SortedSet:
+		return obj.Count() == 0, nil
+	case HashSet:
+		return obj.Count() == 0, nil
 	case nil:
 		return true, nil
 	default:
@@ -252,11 +1021,19 @@ func empty_Q(a []MalType) (MalType, error) {
 func count(a []MalType) (MalType, error) {
 	switch obj := a[0].(type) {
 	case List:
-		return len(obj.Val), nil
+		return obj.Count(), nil
 	case Vector:
-		return len(obj.Val), nil
-	case map[string]MalType:
-		return len(obj), nil
+		return obj.Count(), nil
+	case HashMap:
+		return obj.Count(), nil
+	case SortedMap:
+		return obj.Count(), nil
+	case SortedSet:
+		return obj.Count(), nil
+	case HashSet:
+		return obj.Count(), nil
+	case string:
+		return utf8.RuneCountInString(obj), nil
 	case nil:
 		return 0, nil
 	default:
@@ -295,7 +1072,7 @@ func do_map(a []MalType) (MalType, error) {
 			return nil, e
 		}
 	}
-	return List{results, nil}, nil
+	return List{Val: results}, nil
 }
 
 func conj(a []MalType) (MalType, error) {
@@ -304,31 +1081,57 @@ func conj(a []MalType) (MalType, error) {
 	}
 	switch seq := a[0].(type) {
 	case List:
-		new_slc := []MalType{}
-		for i := len(a) - 1; i > 0; i -= 1 {
-			new_slc = append(new_slc, a[i])
+		new_lst := seq
+		for _, x := range a[1:] {
+			new_lst = Cons(x, new_lst)
 		}
-		return List{append(new_slc, seq.Val...), nil}, nil
+		return new_lst, nil
 	case Vector:
-		new_slc := seq.Val
+		new_vec := seq
 		for _, x := range a[1:] {
-			new_slc = append(new_slc, x)
+			new_vec = new_vec.Conj(x)
 		}
-		return Vector{new_slc, nil}, nil
-	}
-
-	if !HashMap_Q(a[0]) {
-		return nil, errors.New("dissoc called on non-hash map")
-	}
-	new_hm := copy_hash_map(a[0].(HashMap))
-	for i := 1; i < len(a); i += 1 {
-		key := a[i]
-		if !String_Q(key) {
-			return nil, errors.New("dissoc called with non-string key")
+		return new_vec, nil
+	case SortedSet:
+		new_ss := seq
+		for _, x := range a[1:] {
+			var e error
+			if new_ss, e = new_ss.Conj(x); e != nil {
+				return nil, e
+			}
 		}
-		delete(new_hm.Val, key.(string))
+		return new_ss, nil
+	case HashSet:
+		new_hs := seq
+		for _, x := range a[1:] {
+			new_hs = new_hs.Conj(x)
+		}
+		return new_hs, nil
+	case HashMap, SortedMap:
+		// a map is conjoined with [key value] entries or other maps
+		kvs := []MalType{seq}
+		for _, x := range a[1:] {
+			switch tx := x.(type) {
+			case Vector:
+				if tx.Count() != 2 {
+					return nil, errors.New("conj called with hash-map entry not of length 2")
+				}
+				kvs = append(kvs, tx.Nth(0), tx.Nth(1))
+			case HashMap, SortedMap:
+				ents, _ := entries("conj", tx)
+				for _, ent := range ents {
+					kvs = append(kvs, ent.Key, ent.Val)
+				}
+			default:
+				return nil, errors.New("conj called on hash-map with non-entry")
+			}
+		}
+		if len(kvs) == 1 {
+			return seq, nil
+		}
+		return assoc(kvs)
 	}
-	return new_hm, nil
+	return nil, errors.New("conj called on non-sequence")
 }
 
 func seq(a []MalType) (MalType, error) {
@@ -337,15 +1140,15 @@ func seq(a []MalType) (MalType, error) {
 	}
 	switch arg := a[0].(type) {
 	case List:
-		if len(arg.Val) == 0 {
+		if arg.Count() == 0 {
 			return nil, nil
 		}
 		return arg, nil
 	case Vector:
-		if len(arg.Val) == 0 {
+		if arg.Count() == 0 {
 			return nil, nil
 		}
-		return List{arg.Val, nil}, nil
+		return List{Val: arg.Slice()}, nil
 	case string:
 		if len(arg) == 0 {
 			return nil, nil
@@ -354,9 +1157,30 @@ func seq(a []MalType) (MalType, error) {
 		for _, ch := range strings.Split(arg, "") {
 			new_slc = append(new_slc, ch)
 		}
-		return List{new_slc, nil}, nil
+		return List{Val: new_slc}, nil
+	case HashMap, SortedMap:
+		// the entries of a map are [key value] vectors
+		ents, _ := entries("seq", arg)
+		if len(ents) == 0 {
+			return nil, nil
+		}
+		new_slc := []MalType{}
+		for _, ent := range ents {
+			new_slc = append(new_slc, NewVector(ent.Key, ent.Val))
+		}
+		return List{Val: new_slc}, nil
+	case SortedSet:
+		if arg.Count() == 0 {
+			return nil, nil
+		}
+		return List{Val: arg.Slice()}, nil
+	case HashSet:
+		if arg.Count() == 0 {
+			return nil, nil
+		}
+		return List{Val: arg.Slice()}, nil
 	}
-	return nil, errors.New("seq requires string or list or vector or nil")
+	return nil, errors.New("seq requires string or list or vector or map or set or nil")
 }
 
 // Metadata functions
@@ -365,11 +1189,23 @@ func with_meta(a []MalType) (MalType, error) {
 	m := a[1]
 	switch tobj := obj.(type) {
 	case List:
-		return List{tobj.Val, m}, nil
+		tobj.Meta = m
+		return tobj, nil
 	case Vector:
-		return Vector{tobj.Val, m}, nil
+		tobj.Meta = m
+		return tobj, nil
 	case HashMap:
-		return HashMap{tobj.Val, m}, nil
+		tobj.Meta = m
+		return tobj, nil
+	case SortedMap:
+		tobj.Meta = m
+		return tobj, nil
+	case SortedSet:
+		tobj.Meta = m
+		return tobj, nil
+	case HashSet:
+		tobj.Meta = m
+		return tobj, nil
 	case Func:
 		return Func{tobj.Fn, m}, nil
 	case MalFunc:
@@ -390,6 +1226,12 @@ func meta(a []MalType) (MalType, error) {
 		return tobj.Meta, nil
 	case HashMap:
 		return tobj.Meta, nil
+	case SortedMap:
+		return tobj.Meta, nil
+	case SortedSet:
+		return tobj.Meta, nil
+	case HashSet:
+		return tobj.Meta, nil
 	case Func:
 		return tobj.Meta, nil
 	case MalFunc:
@@ -433,72 +1275,105 @@ func swap_BANG(a []MalType) (MalType, error) {
 
 // core namespace
 var NS = map[string]MalType{
-	"=":       call2b(Equal_Q),
-	"throw":   call1e(throw),
-	"nil?":    call1b(Nil_Q),
-	"true?":   call1b(True_Q),
-	"false?":  call1b(False_Q),
-	"symbol":  call1e(func(a []MalType) (MalType, error) { return Symbol{a[0].(string)}, nil }),
-	"symbol?": call1b(Symbol_Q),
-	"string?": call1e(func(a []MalType) (MalType, error) { return (String_Q(a[0]) && !Keyword_Q(a[0])), nil }),
-	"keyword": call1e(func(a []MalType) (MalType, error) {
-		if Keyword_Q(a[0]) {
-			return a[0], nil
-		} else {
-			return NewKeyword(a[0].(string))
-		}
-	}),
-	"keyword?":    call1b(Keyword_Q),
-	"number?":     call1b(Number_Q),
-	"fn?":         call1e(fn_q),
-	"macro?":      call1e(func(a []MalType) (MalType, error) { return MalFunc_Q(a[0]) && a[0].(MalFunc).GetMacro(), nil }),
-	"pr-str":      callNe(pr_str),
-	"str":         callNe(str),
-	"prn":         callNe(prn),
-	"println":     callNe(println),
-	"read-string": call1e(func(a []MalType) (MalType, error) { return reader.Read_str(a[0].(string)) }),
-	"slurp":       call1e(slurp),
-	"readline":    call1e(func(a []MalType) (MalType, error) { return readline.Readline(a[0].(string)) }),
-	"<":           call2e(func(a []MalType) (MalType, error) { return a[0].(int) < a[1].(int), nil }),
-	"<=":          call2e(func(a []MalType) (MalType, error) { return a[0].(int) <= a[1].(int), nil }),
-	">":           call2e(func(a []MalType) (MalType, error) { return a[0].(int) > a[1].(int), nil }),
-	">=":          call2e(func(a []MalType) (MalType, error) { return a[0].(int) >= a[1].(int), nil }),
-	"+":           call2e(func(a []MalType) (MalType, error) { return a[0].(int) + a[1].(int), nil }),
-	"-":           call2e(func(a []MalType) (MalType, error) { return a[0].(int) - a[1].(int), nil }),
-	"*":           call2e(func(a []MalType) (MalType, error) { return a[0].(int) * a[1].(int), nil }),
-	"/":           call2e(func(a []MalType) (MalType, error) { return a[0].(int) / a[1].(int), nil }),
-	"time-ms":     call0e(time_ms),
-	"list":        callNe(func(a []MalType) (MalType, error) { return List{a, nil}, nil }),
-	"list?":       call1b(List_Q),
-	"vector":      callNe(func(a []MalType) (MalType, error) { return Vector{a, nil}, nil }),
-	"vector?":     call1b(Vector_Q),
-	"hash-map":    callNe(func(a []MalType) (MalType, error) { return NewHashMap(List{a, nil}) }),
-	"map?":        call1b(HashMap_Q),
-	"assoc":       callNe(assoc),  // at least 3
-	"dissoc":      callNe(dissoc), // at least 2
-	"get":         call2e(get),
-	"contains?":   call2e(func(a []MalType) (MalType, error) { return contains_Q(a[0], a[1]) }),
-	"keys":        call1e(keys),
-	"vals":        call1e(vals),
-	"sequential?": call1b(Sequential_Q),
-	"cons":        call2e(cons),
-	"concat":      callNe(concat),
-	"nth":         call2e(nth),
-	"first":       call1e(first),
-	"rest":        call1e(rest),
-	"empty?":      call1e(empty_Q),
-	"count":       call1e(count),
-	"apply":       callNe(apply), // at least 2
-	"map":         call2e(do_map),
-	"conj":        callNe(conj), // at least 2
-	"seq":         call1e(seq),
-	"with-meta":   call2e(with_meta),
-	"meta":        call1e(meta),
-	"atom":        call1e(func(a []MalType) (MalType, error) { return &Atom{a[0], nil}, nil }),
-	"atom?":       call1b(Atom_Q),
-	"deref":       call1e(deref),
-	"reset!":      call2e(reset_BANG),
-	"swap!":       callNe(swap_BANG),
+	"=":             call2b(Equal_Q),
+	"throw":         call1e(throw),
+	"nil?":          call1b(Nil_Q),
+	"true?":         call1b(True_Q),
+	"false?":        call1b(False_Q),
+	"symbol":        call1e(func(a []MalType) (MalType, error) { return Symbol{a[0].(string)}, nil }),
+	"symbol?":       call1b(Symbol_Q),
+	"string?":       call1b(String_Q),
+	"keyword":       callNe(keyword),
+	"keyword?":      call1b(Keyword_Q),
+	"name":          call1e(name),
+	"namespace":     call1e(namespace),
+	"number?":       call1b(Number_Q),
+	"fn?":           call1e(fn_q),
+	"macro?":        call1e(func(a []MalType) (MalType, error) { return MalFunc_Q(a[0]) && a[0].(MalFunc).GetMacro(), nil }),
+	"pr-str":        callNe(pr_str),
+	"str":           callNe(str),
+	"prn":           callNe(prn),
+	"println":       callNe(println),
+	"read-string":   call1e(func(a []MalType) (MalType, error) { return reader.Read_str(a[0].(string)) }),
+	"slurp":         call1e(slurp),
+	"read-file":     call1e(read_file),
+	"readline":      call1e(func(a []MalType) (MalType, error) { return readline.Readline(a[0].(string)) }),
+	"subs":          call23e(subs),
+	"char":          call1e(char),
+	"code-point":    call1e(code_point),
+	"re-pattern":    call1e(re_pattern),
+	"re-find":       call2e(re_find),
+	"re-matches":    call2e(re_matches),
+	"re-seq":        call2e(re_seq),
+	"<":             call1Ne(compare("<", func(c int) bool { return c == -1 })),
+	"<=":            call1Ne(compare("<=", func(c int) bool { return c == -1 || c == 0 })),
+	">":             call1Ne(compare(">", func(c int) bool { return c == 1 })),
+	">=":            call1Ne(compare(">=", func(c int) bool { return c == 1 || c == 0 })),
+	"+":             callNe(fold("+", Add, 0)),
+	"-":             call1Ne(sub),
+	"*":             callNe(fold("*", Mul, 1)),
+	"/":             call1Ne(div),
+	"time-ms":       call0e(time_ms),
+	"list":          callNe(func(a []MalType) (MalType, error) { return List{Val: a}, nil }),
+	"list?":         call1b(List_Q),
+	"vector":        callNe(func(a []MalType) (MalType, error) { return NewVector(a...), nil }),
+	"vector?":       call1b(Vector_Q),
+	"hash-map":      callNe(func(a []MalType) (MalType, error) { return NewHashMap(List{Val: a}) }),
+	"map?":          call1b(func(obj MalType) bool { return HashMap_Q(obj) || SortedMap_Q(obj) }),
+	"assoc":         callNe(assoc),  // at least 3
+	"dissoc":        callNe(dissoc), // at least 2
+	"get":           call2e(get),
+	"contains?":     call2e(func(a []MalType) (MalType, error) { return contains_Q(a[0], a[1]) }),
+	"keys":          call1e(keys),
+	"vals":          call1e(vals),
+	"hash-set":      callNe(func(a []MalType) (MalType, error) { return NewHashSet(a...), nil }),
+	"set":           call1e(to_set),
+	"set?":          call1b(Set_Q),
+	"disj":          call1Ne(disj),
+	"union":         callNe(union),
+	"intersection":  call1Ne(intersection),
+	"difference":    call1Ne(difference),
+	"subset?":       call2e(subset_Q),
+	"select":        call2e(do_select),
+	"sorted-map":    callNe(func(a []MalType) (MalType, error) { return sorted_map(nil, a) }),
+	"sorted-map-by": call1Ne(func(a []MalType) (MalType, error) { return sorted_map(NewFnComparator(a[0]), a[1:]) }),
+	"sorted-set":    callNe(func(a []MalType) (MalType, error) { return sorted_set(nil, a) }),
+	"sorted-set-by": call1Ne(func(a []MalType) (MalType, error) { return sorted_set(NewFnComparator(a[0]), a[1:]) }),
+	"sorted?":       call1b(sorted_Q),
+	"compare":       call2e(do_compare),
+	"subseq":        callNe(subseq("subseq", false)),
+	"rsubseq":       callNe(subseq("rsubseq", true)),
+	"sequential?":   call1b(Sequential_Q),
+	"cons":          call2e(cons),
+	"concat":        callNe(concat),
+	"nth":           call2e(nth),
+	"first":         call1e(first),
+	"rest":          call1e(rest),
+	"empty?":        call1e(empty_Q),
+	"count":         call1e(count),
+	"apply":         callNe(apply), // at least 2
+	"map":           call2e(do_map),
+	"conj":          callNe(conj), // at least 2
+	"seq":           call1e(seq),
+	"with-meta":     call2e(with_meta),
+	"meta":          call1e(meta),
+	"atom":          call1e(func(a []MalType) (MalType, error) { return &Atom{a[0], nil}, nil }),
+	"atom?":         call1b(Atom_Q),
+	"deref":         call1e(deref),
+	"reset!":        call2e(reset_BANG),
+	"swap!":         callNe(swap_BANG),
+
+	// string library
+	"string/split":        call23e(split),
+	"string/join":         call12e(join),
+	"string/trim":         call1e(string_fn("trim", strings.TrimSpace)),
+	"string/upper-case":   call1e(string_fn("upper-case", strings.ToUpper)),
+	"string/lower-case":   call1e(string_fn("lower-case", strings.ToLower)),
+	"string/starts-with?": call2e(string_test("starts-with?", strings.HasPrefix)),
+	"string/ends-with?":   call2e(string_test("ends-with?", strings.HasSuffix)),
+	"string/includes?":    call2e(string_test("includes?", strings.Contains)),
+	"string/index-of":     call23e(index_of),
+	"string/replace":      call3e(replace),
 }
 
 // callXX functions check the number of arguments
@@ -529,6 +1404,33 @@ func call2e(f func([]MalType) (MalType, error)) func([]MalType) (MalType, error)
 	}
 }
 
+func call3e(f func([]MalType) (MalType, error)) func([]MalType) (MalType, error) {
+	return func(args []MalType) (MalType, error) {
+		if len(args) != 3 {
+			return nil, fmt.Errorf("wrong number of arguments (%d instead of 3)", len(args))
+		}
+		return f(args)
+	}
+}
+
+func call12e(f func([]MalType) (MalType, error)) func([]MalType) (MalType, error) {
+	return func(args []MalType) (MalType, error) {
+		if len(args) != 1 && len(args) != 2 {
+			return nil, fmt.Errorf("wrong number of arguments (%d instead of 1 or 2)", len(args))
+		}
+		return f(args)
+	}
+}
+
+func call23e(f func([]MalType) (MalType, error)) func([]MalType) (MalType, error) {
+	return func(args []MalType) (MalType, error) {
+		if len(args) != 2 && len(args) != 3 {
+			return nil, fmt.Errorf("wrong number of arguments (%d instead of 2 or 3)", len(args))
+		}
+		return f(args)
+	}
+}
+
 func callNe(f func([]MalType) (MalType, error)) func([]MalType) (MalType, error) {
 	// just for documenting purposes, does not check anything
 	return func(args []MalType) (MalType, error) {
@@ -536,6 +1438,15 @@ func callNe(f func([]MalType) (MalType, error)) func([]MalType) (MalType, error)
 	}
 }
 
+func call1Ne(f func([]MalType) (MalType, error)) func([]MalType) (MalType, error) {
+	return func(args []MalType) (MalType, error) {
+		if len(args) == 0 {
+			return nil, errors.New("wrong number of arguments (0 instead of at least 1)")
+		}
+		return f(args)
+	}
+}
+
 func call1b(f func(MalType) bool) func([]MalType) (MalType, error) {
 	return func(args []MalType) (MalType, error) {
 		if len(args) != 1 {
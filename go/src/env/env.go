@@ -30,7 +30,7 @@ func NewEnv(outer EnvType, binds_mt MalType, exprs_mt MalType) (EnvType, error)
 		// corresponding values in exprs
 		for i := 0; i < len(binds); i += 1 {
 			if Symbol_Q(binds[i]) && binds[i].(Symbol).Val == "&" {
-				env.data[binds[i+1].(Symbol).Val] = List{exprs[i:], nil}
+				env.data[binds[i+1].(Symbol).Val] = List{Val: exprs[i:]}
 				break
 			} else {
 				env.data[binds[i].(Symbol).Val] = exprs[i]
@@ -2,6 +2,9 @@ package printer
 
 import (
 	"fmt"
+	"math"
+	"math/big"
+	"strconv"
 	"strings"
 )
 
@@ -21,20 +24,21 @@ func Pr_list(lst []types.MalType, pr bool,
 func Pr_str(obj types.MalType, print_readably bool) string {
 	switch tobj := obj.(type) {
 	case types.List:
-		return Pr_list(tobj.Val, print_readably, "(", ")", " ")
+		return Pr_list(tobj.Slice(), print_readably, "(", ")", " ")
 	case types.Vector:
-		return Pr_list(tobj.Val, print_readably, "[", "]", " ")
+		return Pr_list(tobj.Slice(), print_readably, "[", "]", " ")
 	case types.HashMap:
-		str_list := make([]string, 0, len(tobj.Val)*2)
-		for k, v := range tobj.Val {
-			str_list = append(str_list, Pr_str(k, print_readably))
-			str_list = append(str_list, Pr_str(v, print_readably))
-		}
-		return "{" + strings.Join(str_list, " ") + "}"
+		return pr_entries(tobj.Entries(), print_readably)
+	case types.SortedMap:
+		return pr_entries(tobj.Entries(), print_readably)
+	case types.HashSet:
+		return Pr_list(tobj.Slice(), print_readably, "#{", "}", " ")
+	case types.SortedSet:
+		return Pr_list(tobj.Slice(), print_readably, "#{", "}", " ")
+	case types.Keyword:
+		return tobj.String()
 	case string:
-		if strings.HasPrefix(tobj, "\u029e") {
-			return ":" + tobj[2:len(tobj)]
-		} else if print_readably {
+		if print_readably {
 			return `"` + strings.Replace(
 				strings.Replace(
 					strings.Replace(tobj, `\`, `\\`, -1),
@@ -45,6 +49,30 @@ func Pr_str(obj types.MalType, print_readably bool) string {
 		}
 	case types.Symbol:
 		return tobj.Val
+	case types.Regex:
+		if print_readably {
+			return `#"` + pr_pattern(tobj.String()) + `"`
+		}
+		return tobj.String()
+	case float64:
+		// keep a float looking like a float, so that it reads back as a float
+		switch {
+		case math.IsInf(tobj, 1):
+			return "##Inf"
+		case math.IsInf(tobj, -1):
+			return "##-Inf"
+		case math.IsNaN(tobj):
+			return "##NaN"
+		}
+		s := strconv.FormatFloat(tobj, 'g', -1, 64)
+		if !strings.ContainsAny(s, ".e") {
+			s += ".0"
+		}
+		return s
+	case *big.Int:
+		return tobj.String()
+	case *big.Rat:
+		return tobj.RatString()
 	case nil:
 		return "nil"
 	case types.MalFunc:
@@ -60,3 +88,33 @@ func Pr_str(obj types.MalType, print_readably bool) string {
 		return fmt.Sprintf("%v", obj)
 	}
 }
+
+func pr_entries(ents []types.MapEntry, print_readably bool) string {
+	str_list := make([]string, 0, len(ents)*2)
+	for _, ent := range ents {
+		str_list = append(str_list, Pr_str(ent.Key, print_readably))
+		str_list = append(str_list, Pr_str(ent.Val, print_readably))
+	}
+	return "{" + strings.Join(str_list, " ") + "}"
+}
+
+// pr_pattern escapes the quotes in a regex pattern which aren't already,
+// so that the printed regex reads back as one matching the same strings
+func pr_pattern(pattern string) string {
+	var b strings.Builder
+	for i := 0; i < len(pattern); i++ {
+		switch pattern[i] {
+		case '\\':
+			b.WriteByte('\\')
+			if i+1 < len(pattern) {
+				i++
+				b.WriteByte(pattern[i])
+			}
+		case '"':
+			b.WriteString(`\"`)
+		default:
+			b.WriteByte(pattern[i])
+		}
+	}
+	return b.String()
+}
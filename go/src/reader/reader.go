@@ -2,6 +2,8 @@ package reader
 
 import (
 	"errors"
+	"math"
+	"math/big"
 	"regexp"
 	"strconv"
 	"strings"
@@ -15,10 +17,20 @@ import (
 type Reader interface {
 	next() *string
 	peek() *string
+	loc() location
+}
+
+// token is a token with the line and column, counting from 1, at which it
+// starts in the source
+type token struct {
+	val  string
+	line int
+	col  int
 }
 
 type TokenReader struct {
-	tokens   []string
+	file     string
+	tokens   []token
 	position int
 }
 
@@ -26,7 +38,7 @@ func (tr *TokenReader) next() *string {
 	if tr.position >= len(tr.tokens) {
 		return nil
 	}
-	token := tr.tokens[tr.position]
+	token := tr.tokens[tr.position].val
 	tr.position = tr.position + 1
 	return &token
 }
@@ -35,36 +47,158 @@ func (tr *TokenReader) peek() *string {
 	if tr.position >= len(tr.tokens) {
 		return nil
 	}
-	return &tr.tokens[tr.position]
+	return &tr.tokens[tr.position].val
+}
+
+// loc is the location of the next token, or of the last one at the end
+func (tr *TokenReader) loc() location {
+	i := tr.position
+	if i >= len(tr.tokens) {
+		i = len(tr.tokens) - 1
+	}
+	return location{tr.file, tr.tokens[i].line, tr.tokens[i].col}
+}
+
+// location is where a form starts in a file, which is printed as
+// file.mal:123:7
+type location struct {
+	file string
+	line int
+	col  int
+}
+
+func (l location) String() string {
+	return l.file + ":" + strconv.Itoa(l.line) + ":" + strconv.Itoa(l.col)
+}
+
+// error is msg at l, which is only shown for files: a string read by
+// read-string or at the REPL is short enough without it
+func (l location) error(msg string) error {
+	if l.file == "" {
+		return errors.New(msg)
+	}
+	return locError{l, errors.New(msg)}
+}
+
+// locError is an error in reading or evaluating the form at loc
+type locError struct {
+	loc location
+	err error
+}
+
+func (e locError) Error() string {
+	return e.loc.String() + ": " + e.err.Error()
+}
+
+var kw_file, _ = NewKeyword("file")
+var kw_line, _ = NewKeyword("line")
+var kw_column, _ = NewKeyword("column")
+
+// meta is the metadata of a collection read at l, {:file "file.mal" :line
+// 123 :column 7}, without the :file when it wasn't read from a file
+func (l location) meta() MalType {
+	hm := HashMap{}.Assoc(kw_line, l.line).Assoc(kw_column, l.col)
+	if l.file != "" {
+		hm = hm.Assoc(kw_file, l.file)
+	}
+	return hm
+}
+
+// Locate adds the location of ast, when it was read from a file, to the
+// error e in evaluating it. An error that already has a location, from a
+// form inside ast, is kept, and so is a value thrown by the program.
+func Locate(ast MalType, e error) error {
+	switch e.(type) {
+	case locError, MalError:
+		return e
+	}
+	var meta MalType
+	switch a := ast.(type) {
+	case List:
+		meta = a.Meta
+	case Vector:
+		meta = a.Meta
+	case HashMap:
+		meta = a.Meta
+	}
+	hm, ok := meta.(HashMap)
+	if !ok {
+		return e
+	}
+	file, _ := hm.Get(kw_file)
+	line, _ := hm.Get(kw_line)
+	col, _ := hm.Get(kw_column)
+	f, ok1 := file.(string)
+	l, ok2 := line.(int)
+	c, ok3 := col.(int)
+	if !ok1 || !ok2 || !ok3 {
+		return e
+	}
+	return locError{location{f, l, c}, e}
 }
 
-func tokenize(str string) []string {
-	results := make([]string, 0, 1)
+func tokenize(str string) []token {
+	results := make([]token, 0, 1)
 	// Work around lack of quoting in backtick
-	re := regexp.MustCompile(`[\s,]*(~@|[\[\]{}()'` + "`" +
+	re := regexp.MustCompile(`[\s,]*(~@|#\{|#"(?:\\.|[^\\"])*"|[\[\]{}()'` + "`" +
 		`~^@]|"(?:\\.|[^\\"])*"|;.*|[^\s\[\]{}('"` + "`" +
 		`,;)]*)`)
-	for _, group := range re.FindAllStringSubmatch(str, -1) {
-		if (group[1] == "") || (group[1][0] == ';') {
+	line, col, last := 1, 1, 0
+	for _, m := range re.FindAllStringSubmatchIndex(str, -1) {
+		val := str[m[2]:m[3]]
+		if (val == "") || (val[0] == ';') {
 			continue
 		}
-		results = append(results, group[1])
+		for _, c := range str[last:m[2]] {
+			if c == '\n' {
+				line, col = line+1, 1
+			} else {
+				col++
+			}
+		}
+		last = m[2]
+		results = append(results, token{val, line, col})
 	}
 	return results
 }
 
 func read_atom(rdr Reader) (MalType, error) {
+	loc := rdr.loc()
 	token := rdr.next()
 	if token == nil {
-		return nil, errors.New("read_atom underflow")
+		return nil, loc.error("read_atom underflow")
 	}
 	if match, _ := regexp.MatchString(`^-?[0-9]+$`, *token); match {
-		var i int
-		var e error
-		if i, e = strconv.Atoi(*token); e != nil {
-			return nil, errors.New("number parse error")
+		i, ok := new(big.Int).SetString(*token, 10)
+		if !ok {
+			return nil, loc.error("number parse error")
+		}
+		return NewBigInt(i), nil
+	} else if match, _ := regexp.MatchString(`^-?[0-9]+/[0-9]+$`, *token); match {
+		r, ok := new(big.Rat).SetString(*token)
+		if !ok {
+			return nil, loc.error("number parse error")
+		}
+		return NewRatio(r), nil
+	} else if match, _ := regexp.MatchString(`^-?[0-9]+(\.[0-9]*)?([eE][-+]?[0-9]+)?$`, *token); match {
+		f, e := strconv.ParseFloat(*token, 64)
+		if e != nil && !math.IsInf(f, 0) {
+			return nil, loc.error("number parse error")
+		}
+		return f, nil
+	} else if *token == "##Inf" {
+		return math.Inf(1), nil
+	} else if *token == "##-Inf" {
+		return math.Inf(-1), nil
+	} else if *token == "##NaN" {
+		return math.NaN(), nil
+	} else if strings.HasPrefix(*token, `#"`) {
+		// the pattern is read as it is, so #"\d" matches a digit
+		re, e := NewRegex((*token)[2 : len(*token)-1])
+		if e != nil {
+			return nil, loc.error(e.Error())
 		}
-		return i, nil
+		return re, nil
 	} else if (*token)[0] == '"' {
 		str := (*token)[1 : len(*token)-1]
 		return strings.Replace(
@@ -75,7 +209,11 @@ func read_atom(rdr Reader) (MalType, error) {
 			 `\n`, "\n", -1),
 			"\u029e", "\\", -1), nil
 	} else if (*token)[0] == ':' {
-		return NewKeyword((*token)[1:len(*token)])
+		k, e := NewKeyword((*token)[1:len(*token)])
+		if e != nil {
+			return nil, loc.error(e.Error())
+		}
+		return k, nil
 	} else if *token == "nil" {
 		return nil, nil
 	} else if *token == "true" {
@@ -89,19 +227,20 @@ func read_atom(rdr Reader) (MalType, error) {
 }
 
 func read_list(rdr Reader, start string, end string) (MalType, error) {
+	loc := rdr.loc()
 	token := rdr.next()
 	if token == nil {
-		return nil, errors.New("read_list underflow")
+		return nil, loc.error("read_list underflow")
 	}
 	if *token != start {
-		return nil, errors.New("expected '" + start + "'")
+		return nil, loc.error("expected '" + start + "'")
 	}
 
 	ast_list := []MalType{}
 	token = rdr.peek()
 	for ; true; token = rdr.peek() {
 		if token == nil {
-			return nil, errors.New("exepected '" + end + "', got EOF")
+			return nil, loc.error("expected '" + end + "', got EOF")
 		}
 		if *token == end {
 			break
@@ -113,7 +252,7 @@ func read_list(rdr Reader, start string, end string) (MalType, error) {
 		ast_list = append(ast_list, f)
 	}
 	rdr.next()
-	return List{ast_list, nil}, nil
+	return List{Val: ast_list, Meta: loc.meta()}, nil
 }
 
 func read_vector(rdr Reader) (MalType, error) {
@@ -121,22 +260,40 @@ func read_vector(rdr Reader) (MalType, error) {
 	if e != nil {
 		return nil, e
 	}
-	vec := Vector{lst.(List).Val, nil}
+	vec := NewVector(lst.(List).Val...)
+	vec.Meta = lst.(List).Meta
 	return vec, nil
 }
 
 func read_hash_map(rdr Reader) (MalType, error) {
+	loc := rdr.loc()
 	mal_lst, e := read_list(rdr, "{", "}")
 	if e != nil {
 		return nil, e
 	}
-	return NewHashMap(mal_lst)
+	hm, e := NewHashMap(mal_lst)
+	if e != nil {
+		return nil, loc.error(e.Error())
+	}
+	m := hm.(HashMap)
+	m.Meta = mal_lst.(List).Meta
+	return m, nil
+}
+
+func read_hash_set(rdr Reader) (MalType, error) {
+	mal_lst, e := read_list(rdr, "#{", "}")
+	if e != nil {
+		return nil, e
+	}
+	set := NewHashSet(mal_lst.(List).Val...)
+	set.Meta = mal_lst.(List).Meta
+	return set, nil
 }
 
 func read_form(rdr Reader) (MalType, error) {
 	token := rdr.peek()
 	if token == nil {
-		return nil, errors.New("read_form underflow")
+		return nil, rdr.loc().error("read_form underflow")
 	}
 	switch *token {
 
@@ -146,28 +303,28 @@ func read_form(rdr Reader) (MalType, error) {
 		if e != nil {
 			return nil, e
 		}
-		return List{[]MalType{Symbol{"quote"}, form}, nil}, nil
+		return List{Val: []MalType{Symbol{"quote"}, form}}, nil
 	case "`":
 		rdr.next()
 		form, e := read_form(rdr)
 		if e != nil {
 			return nil, e
 		}
-		return List{[]MalType{Symbol{"quasiquote"}, form}, nil}, nil
+		return List{Val: []MalType{Symbol{"quasiquote"}, form}}, nil
 	case `~`:
 		rdr.next()
 		form, e := read_form(rdr)
 		if e != nil {
 			return nil, e
 		}
-		return List{[]MalType{Symbol{"unquote"}, form}, nil}, nil
+		return List{Val: []MalType{Symbol{"unquote"}, form}}, nil
 	case `~@`:
 		rdr.next()
 		form, e := read_form(rdr)
 		if e != nil {
 			return nil, e
 		}
-		return List{[]MalType{Symbol{"splice-unquote"}, form}, nil}, nil
+		return List{Val: []MalType{Symbol{"splice-unquote"}, form}}, nil
 	case `^`:
 		rdr.next()
 		meta, e := read_form(rdr)
@@ -178,32 +335,36 @@ func read_form(rdr Reader) (MalType, error) {
 		if e != nil {
 			return nil, e
 		}
-		return List{[]MalType{Symbol{"with-meta"}, form, meta}, nil}, nil
+		return List{Val: []MalType{Symbol{"with-meta"}, form, meta}}, nil
 	case `@`:
 		rdr.next()
 		form, e := read_form(rdr)
 		if e != nil {
 			return nil, e
 		}
-		return List{[]MalType{Symbol{"deref"}, form}, nil}, nil
+		return List{Val: []MalType{Symbol{"deref"}, form}}, nil
 
 	// list
 	case ")":
-		return nil, errors.New("unexpected ')'")
+		return nil, rdr.loc().error("unexpected ')'")
 	case "(":
 		return read_list(rdr, "(", ")")
 
 	// vector
 	case "]":
-		return nil, errors.New("unexpected ']'")
+		return nil, rdr.loc().error("unexpected ']'")
 	case "[":
 		return read_vector(rdr)
 
 	// hash-map
 	case "}":
-		return nil, errors.New("unexpected '}'")
+		return nil, rdr.loc().error("unexpected '}'")
 	case "{":
 		return read_hash_map(rdr)
+
+	// hash-set
+	case "#{":
+		return read_hash_set(rdr)
 	default:
 		return read_atom(rdr)
 	}
@@ -218,3 +379,18 @@ func Read_str(str string) (MalType, error) {
 
 	return read_form(&TokenReader{tokens: tokens, position: 0})
 }
+
+// Read_file reads all the forms in str, which is the source of file, so
+// that their locations and errors are in file
+func Read_file(str string, file string) ([]MalType, error) {
+	rdr := &TokenReader{file: file, tokens: tokenize(str), position: 0}
+	forms := []MalType{}
+	for rdr.peek() != nil {
+		form, e := read_form(rdr)
+		if e != nil {
+			return nil, e
+		}
+		forms = append(forms, form)
+	}
+	return forms, nil
+}
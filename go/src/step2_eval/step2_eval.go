@@ -30,42 +30,49 @@ func eval_ast(ast MalType, env map[string]MalType) (MalType, error) {
 		return exp, nil
 	} else if List_Q(ast) {
 		lst := []MalType{}
-		for _, a := range ast.(List).Val {
+		for _, a := range ast.(List).Slice() {
 			exp, e := EVAL(a, env)
 			if e != nil {
 				return nil, e
 			}
 			lst = append(lst, exp)
 		}
-		return List{lst, nil}, nil
+		return List{Val: lst}, nil
 	} else if Vector_Q(ast) {
 		lst := []MalType{}
-		for _, a := range ast.(Vector).Val {
+		for _, a := range ast.(Vector).Slice() {
 			exp, e := EVAL(a, env)
 			if e != nil {
 				return nil, e
 			}
 			lst = append(lst, exp)
 		}
-		return Vector{lst, nil}, nil
+		return NewVector(lst...), nil
 	} else if HashMap_Q(ast) {
 		m := ast.(HashMap)
-		new_hm := HashMap{map[string]MalType{}, nil}
-		for k, v := range m.Val {
-			ke, e1 := EVAL(k, env)
+		new_hm := HashMap{}
+		for _, ent := range m.Entries() {
+			ke, e1 := EVAL(ent.Key, env)
 			if e1 != nil {
 				return nil, e1
 			}
-			if _, ok := ke.(string); !ok {
-				return nil, errors.New("non string hash-map key")
-			}
-			kv, e2 := EVAL(v, env)
+			kv, e2 := EVAL(ent.Val, env)
 			if e2 != nil {
 				return nil, e2
 			}
-			new_hm.Val[ke.(string)] = kv
+			new_hm = new_hm.Assoc(ke, kv)
 		}
 		return new_hm, nil
+	} else if HashSet_Q(ast) {
+		new_hs := HashSet{}
+		for _, a := range ast.(HashSet).Slice() {
+			exp, e := EVAL(a, env)
+			if e != nil {
+				return nil, e
+			}
+			new_hs = new_hs.Conj(exp)
+		}
+		return new_hs, nil
 	} else {
 		return ast, nil
 	}
@@ -79,7 +86,7 @@ func EVAL(ast MalType, env map[string]MalType) (MalType, error) {
 		return eval_ast(ast, env)
 	}
 
-	if len(ast.(List).Val) == 0 {
+	if len(ast.(List).Slice()) == 0 {
 		return ast, nil
 	}
 
@@ -88,11 +95,11 @@ func EVAL(ast MalType, env map[string]MalType) (MalType, error) {
 	if e != nil {
 		return nil, e
 	}
-	f, ok := el.(List).Val[0].(func([]MalType) (MalType, error))
+	f, ok := el.(List).Slice()[0].(func([]MalType) (MalType, error))
 	if !ok {
 		return nil, errors.New("attempt to call non-function")
 	}
-	return f(el.(List).Val[1:])
+	return f(el.(List).Slice()[1:])
 }
 
 // print
@@ -105,25 +112,25 @@ var repl_env = map[string]MalType{
 		if e := assertArgNum(a, 2); e != nil {
 			return nil, e
 		}
-		return a[0].(int) + a[1].(int), nil
+		return Add(a[0], a[1])
 	},
 	"-": func(a []MalType) (MalType, error) {
 		if e := assertArgNum(a, 2); e != nil {
 			return nil, e
 		}
-		return a[0].(int) - a[1].(int), nil
+		return Sub(a[0], a[1])
 	},
 	"*": func(a []MalType) (MalType, error) {
 		if e := assertArgNum(a, 2); e != nil {
 			return nil, e
 		}
-		return a[0].(int) * a[1].(int), nil
+		return Mul(a[0], a[1])
 	},
 	"/": func(a []MalType) (MalType, error) {
 		if e := assertArgNum(a, 2); e != nil {
 			return nil, e
 		}
-		return a[0].(int) / a[1].(int), nil
+		return Div(a[0], a[1])
 	},
 }
 
@@ -26,42 +26,49 @@ func eval_ast(ast MalType, env EnvType) (MalType, error) {
 		return env.Get(ast.(Symbol))
 	} else if List_Q(ast) {
 		lst := []MalType{}
-		for _, a := range ast.(List).Val {
+		for _, a := range ast.(List).Slice() {
 			exp, e := EVAL(a, env)
 			if e != nil {
 				return nil, e
 			}
 			lst = append(lst, exp)
 		}
-		return List{lst, nil}, nil
+		return List{Val: lst}, nil
 	} else if Vector_Q(ast) {
 		lst := []MalType{}
-		for _, a := range ast.(Vector).Val {
+		for _, a := range ast.(Vector).Slice() {
 			exp, e := EVAL(a, env)
 			if e != nil {
 				return nil, e
 			}
 			lst = append(lst, exp)
 		}
-		return Vector{lst, nil}, nil
+		return NewVector(lst...), nil
 	} else if HashMap_Q(ast) {
 		m := ast.(HashMap)
-		new_hm := HashMap{map[string]MalType{}, nil}
-		for k, v := range m.Val {
-			ke, e1 := EVAL(k, env)
+		new_hm := HashMap{}
+		for _, ent := range m.Entries() {
+			ke, e1 := EVAL(ent.Key, env)
 			if e1 != nil {
 				return nil, e1
 			}
-			if _, ok := ke.(string); !ok {
-				return nil, errors.New("non string hash-map key")
-			}
-			kv, e2 := EVAL(v, env)
+			kv, e2 := EVAL(ent.Val, env)
 			if e2 != nil {
 				return nil, e2
 			}
-			new_hm.Val[ke.(string)] = kv
+			new_hm = new_hm.Assoc(ke, kv)
 		}
 		return new_hm, nil
+	} else if HashSet_Q(ast) {
+		new_hs := HashSet{}
+		for _, a := range ast.(HashSet).Slice() {
+			exp, e := EVAL(a, env)
+			if e != nil {
+				return nil, e
+			}
+			new_hs = new_hs.Conj(exp)
+		}
+		return new_hs, nil
 	} else {
 		return ast, nil
 	}
@@ -75,24 +82,24 @@ func EVAL(ast MalType, env EnvType) (MalType, error) {
 		return eval_ast(ast, env)
 	}
 
-	if len(ast.(List).Val) == 0 {
+	if len(ast.(List).Slice()) == 0 {
 		return ast, nil
 	}
 
 	// apply list
-	a0 := ast.(List).Val[0]
+	a0 := ast.(List).Slice()[0]
 	var a1 MalType = nil
 	var a2 MalType = nil
-	switch len(ast.(List).Val) {
+	switch len(ast.(List).Slice()) {
 	case 1:
 		a1 = nil
 		a2 = nil
 	case 2:
-		a1 = ast.(List).Val[1]
+		a1 = ast.(List).Slice()[1]
 		a2 = nil
 	default:
-		a1 = ast.(List).Val[1]
-		a2 = ast.(List).Val[2]
+		a1 = ast.(List).Slice()[1]
+		a2 = ast.(List).Slice()[2]
 	}
 	a0sym := "__<*fn*>__"
 	if Symbol_Q(a0) {
@@ -130,11 +137,11 @@ func EVAL(ast MalType, env EnvType) (MalType, error) {
 		if e != nil {
 			return nil, e
 		}
-		f, ok := el.(List).Val[0].(func([]MalType) (MalType, error))
+		f, ok := el.(List).Slice()[0].(func([]MalType) (MalType, error))
 		if !ok {
 			return nil, errors.New("attempt to call non-function")
 		}
-		return f(el.(List).Val[1:])
+		return f(el.(List).Slice()[1:])
 	}
 }
 
@@ -167,25 +174,25 @@ func main() {
 		if e := assertArgNum(a, 2); e != nil {
 			return nil, e
 		}
-		return a[0].(int) + a[1].(int), nil
+		return Add(a[0], a[1])
 	})
 	repl_env.Set(Symbol{"-"}, func(a []MalType) (MalType, error) {
 		if e := assertArgNum(a, 2); e != nil {
 			return nil, e
 		}
-		return a[0].(int) - a[1].(int), nil
+		return Sub(a[0], a[1])
 	})
 	repl_env.Set(Symbol{"*"}, func(a []MalType) (MalType, error) {
 		if e := assertArgNum(a, 2); e != nil {
 			return nil, e
 		}
-		return a[0].(int) * a[1].(int), nil
+		return Mul(a[0], a[1])
 	})
 	repl_env.Set(Symbol{"/"}, func(a []MalType) (MalType, error) {
 		if e := assertArgNum(a, 2); e != nil {
 			return nil, e
 		}
-		return a[0].(int) / a[1].(int), nil
+		return Div(a[0], a[1])
 	})
 
 	// repl loop
@@ -32,7 +32,7 @@ func is_pair(x MalType) bool {
 
 func quasiquote(ast MalType) MalType {
 	if !is_pair(ast) {
-		return List{[]MalType{Symbol{"quote"}, ast}, nil}
+		return List{Val: []MalType{Symbol{"quote"}, ast}}
 	} else {
 		slc, _ := GetSlice(ast)
 		a0 := slc[0]
@@ -42,14 +42,14 @@ func quasiquote(ast MalType) MalType {
 			slc0, _ := GetSlice(a0)
 			a00 := slc0[0]
 			if Symbol_Q(a00) && (a00.(Symbol).Val == "splice-unquote") {
-				return List{[]MalType{Symbol{"concat"},
+				return List{Val: []MalType{Symbol{"concat"},
 					slc0[1],
-					quasiquote(List{slc[1:], nil})}, nil}
+					quasiquote(List{Val: slc[1:]})}}
 			}
 		}
-		return List{[]MalType{Symbol{"cons"},
+		return List{Val: []MalType{Symbol{"cons"},
 			quasiquote(a0),
-			quasiquote(List{slc[1:], nil})}, nil}
+			quasiquote(List{Val: slc[1:]})}}
 	}
 }
 
@@ -98,49 +98,62 @@ func eval_ast(ast MalType, env EnvType) (MalType, error) {
 		return env.Get(ast.(Symbol))
 	} else if List_Q(ast) {
 		lst := []MalType{}
-		for _, a := range ast.(List).Val {
+		for _, a := range ast.(List).Slice() {
 			exp, e := EVAL(a, env)
 			if e != nil {
 				return nil, e
 			}
 			lst = append(lst, exp)
 		}
-		return List{lst, nil}, nil
+		return List{Val: lst}, nil
 	} else if Vector_Q(ast) {
 		lst := []MalType{}
-		for _, a := range ast.(Vector).Val {
+		for _, a := range ast.(Vector).Slice() {
 			exp, e := EVAL(a, env)
 			if e != nil {
 				return nil, e
 			}
 			lst = append(lst, exp)
 		}
-		return Vector{lst, nil}, nil
+		return NewVector(lst...), nil
 	} else if HashMap_Q(ast) {
 		m := ast.(HashMap)
-		new_hm := HashMap{map[string]MalType{}, nil}
-		for k, v := range m.Val {
-			ke, e1 := EVAL(k, env)
+		new_hm := HashMap{}
+		for _, ent := range m.Entries() {
+			ke, e1 := EVAL(ent.Key, env)
 			if e1 != nil {
 				return nil, e1
 			}
-			if _, ok := ke.(string); !ok {
-				return nil, errors.New("non string hash-map key")
-			}
-			kv, e2 := EVAL(v, env)
+			kv, e2 := EVAL(ent.Val, env)
 			if e2 != nil {
 				return nil, e2
 			}
-			new_hm.Val[ke.(string)] = kv
+			new_hm = new_hm.Assoc(ke, kv)
 		}
 		return new_hm, nil
+	} else if HashSet_Q(ast) {
+		new_hs := HashSet{}
+		for _, a := range ast.(HashSet).Slice() {
+			exp, e := EVAL(a, env)
+			if e != nil {
+				return nil, e
+			}
+			new_hs = new_hs.Conj(exp)
+		}
+		return new_hs, nil
 	} else {
 		return ast, nil
 	}
 }
 
-func EVAL(ast MalType, env EnvType) (MalType, error) {
-	var e error
+func EVAL(ast MalType, env EnvType) (res MalType, e error) {
+	// the error is at the form being evaluated when it happened, which
+	// changes with the tail calls
+	defer func() {
+		if e != nil {
+			e = reader.Locate(ast, e)
+		}
+	}()
 	for {
 
 		//fmt.Printf("EVAL: %v\n", printer.Pr_str(ast, true))
@@ -158,23 +171,23 @@ func EVAL(ast MalType, env EnvType) (MalType, error) {
 		if !List_Q(ast) {
 			return eval_ast(ast, env)
 		}
-		if len(ast.(List).Val) == 0 {
+		if len(ast.(List).Slice()) == 0 {
 			return ast, nil
 		}
 
-		a0 := ast.(List).Val[0]
+		a0 := ast.(List).Slice()[0]
 		var a1 MalType = nil
 		var a2 MalType = nil
-		switch len(ast.(List).Val) {
+		switch len(ast.(List).Slice()) {
 		case 1:
 			a1 = nil
 			a2 = nil
 		case 2:
-			a1 = ast.(List).Val[1]
+			a1 = ast.(List).Slice()[1]
 			a2 = nil
 		default:
-			a1 = ast.(List).Val[1]
-			a2 = ast.(List).Val[2]
+			a1 = ast.(List).Slice()[1]
+			a2 = ast.(List).Slice()[2]
 		}
 		a0sym := "__<*fn*>__"
 		if Symbol_Q(a0) {
@@ -222,8 +235,8 @@ func EVAL(ast MalType, env EnvType) (MalType, error) {
 		case "macroexpand":
 			return macroexpand(a1, env)
 		case "do":
-			lst := ast.(List).Val
-			_, e := eval_ast(List{lst[1 : len(lst)-1], nil}, env)
+			lst := ast.(List).Slice()
+			_, e := eval_ast(List{Val: lst[1 : len(lst)-1]}, env)
 			if e != nil {
 				return nil, e
 			}
@@ -237,8 +250,8 @@ func EVAL(ast MalType, env EnvType) (MalType, error) {
 				return nil, e
 			}
 			if cond == nil || cond == false {
-				if len(ast.(List).Val) >= 4 {
-					ast = ast.(List).Val[3]
+				if len(ast.(List).Slice()) >= 4 {
+					ast = ast.(List).Slice()[3]
 				} else {
 					return nil, nil
 				}
@@ -253,11 +266,11 @@ func EVAL(ast MalType, env EnvType) (MalType, error) {
 			if e != nil {
 				return nil, e
 			}
-			f := el.(List).Val[0]
+			f := el.(List).Slice()[0]
 			if MalFunc_Q(f) {
 				fn := f.(MalFunc)
 				ast = fn.Exp
-				env, e = NewEnv(fn.Env, fn.Params, List{el.(List).Val[1:], nil})
+				env, e = NewEnv(fn.Env, fn.Params, List{Val: el.(List).Slice()[1:]})
 				if e != nil {
 					return nil, e
 				}
@@ -266,7 +279,7 @@ func EVAL(ast MalType, env EnvType) (MalType, error) {
 				if !ok {
 					return nil, errors.New("attempt to call non-function")
 				}
-				return fn.Fn(el.(List).Val[1:])
+				return fn.Fn(el.(List).Slice()[1:])
 			}
 		}
 
@@ -309,7 +322,7 @@ func main() {
 
 	// core.mal: defined using the language itself
 	rep("(def! not (fn* (a) (if a false true)))")
-	rep("(def! load-file (fn* (f) (eval (read-string (str \"(do \" (slurp f) \")\")))))")
+	rep("(def! load-file (fn* (f) (eval (cons (symbol \"do\") (read-file f)))))")
 	rep("(defmacro! cond (fn* (& xs) (if (> (count xs) 0) (list 'if (first xs) (if (> (count xs) 1) (nth xs 1) (throw \"odd number of forms to cond\")) (cons 'cond (rest (rest xs)))))))")
 	rep("(defmacro! or (fn* (& xs) (if (empty? xs) nil (if (= 1 (count xs)) (first xs) `(let* (or_FIXME ~(first xs)) (if or_FIXME or_FIXME (or ~@(rest xs))))))))")
 
@@ -319,7 +332,7 @@ func main() {
 		for _, a := range os.Args[2:] {
 			args = append(args, a)
 		}
-		repl_env.Set(Symbol{"*ARGV*"}, List{args, nil})
+		repl_env.Set(Symbol{"*ARGV*"}, List{Val: args})
 		if _, e := rep("(load-file \"" + os.Args[1] + "\")"); e != nil {
 			fmt.Printf("Error: %v\n", e)
 			os.Exit(1)
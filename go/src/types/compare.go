@@ -0,0 +1,114 @@
+package types
+
+import (
+	"errors"
+	"math"
+	"strings"
+)
+
+// Compare is a total order over nil, booleans, numbers, strings, keywords,
+// symbols, regexes and sequences, which sort in that order. Numbers are
+// compared by value, with NaN after all the others, strings, keywords and
+// symbols by their names, regexes by their patterns, and sequences element
+// by element. Values of other types can't be compared.
+
+func Compare(a MalType, b MalType) (int, error) {
+	ra, e := compare_rank(a)
+	if e != nil {
+		return 0, e
+	}
+	rb, e := compare_rank(b)
+	if e != nil {
+		return 0, e
+	}
+	if ra != rb {
+		return sign(ra - rb), nil
+	}
+	switch ra {
+	case rankBool:
+		return sign(bool_int(a.(bool)) - bool_int(b.(bool))), nil
+	case rankNumber:
+		if c, _ := NumCmp("compare", a, b); c != 2 {
+			return c, nil
+		}
+		return sign(bool_int(is_nan(a)) - bool_int(is_nan(b))), nil
+	case rankString:
+		return strings.Compare(a.(string), b.(string)), nil
+	case rankKeyword:
+		ka, kb := a.(Keyword), b.(Keyword)
+		if c := strings.Compare(ka.Namespace(), kb.Namespace()); c != 0 {
+			return c, nil
+		}
+		return strings.Compare(ka.Name(), kb.Name()), nil
+	case rankSymbol:
+		return strings.Compare(a.(Symbol).Val, b.(Symbol).Val), nil
+	case rankRegex:
+		return strings.Compare(a.(Regex).String(), b.(Regex).String()), nil
+	case rankSequence:
+		as, _ := GetSlice(a)
+		bs, _ := GetSlice(b)
+		for i := 0; i < len(as) && i < len(bs); i++ {
+			if c, e := Compare(as[i], bs[i]); e != nil || c != 0 {
+				return c, e
+			}
+		}
+		return sign(len(as) - len(bs)), nil
+	}
+	return 0, nil
+}
+
+const (
+	rankNil = iota
+	rankBool
+	rankNumber
+	rankString
+	rankKeyword
+	rankSymbol
+	rankRegex
+	rankSequence
+)
+
+func compare_rank(obj MalType) (int, error) {
+	switch {
+	case obj == nil:
+		return rankNil, nil
+	case Keyword_Q(obj):
+		return rankKeyword, nil
+	case String_Q(obj):
+		return rankString, nil
+	case Number_Q(obj):
+		return rankNumber, nil
+	case Symbol_Q(obj):
+		return rankSymbol, nil
+	case Regex_Q(obj):
+		return rankRegex, nil
+	case Sequential_Q(obj):
+		return rankSequence, nil
+	}
+	if _, ok := obj.(bool); ok {
+		return rankBool, nil
+	}
+	return 0, errors.New("compare called with uncomparable value")
+}
+
+func sign(n int) int {
+	switch {
+	case n < 0:
+		return -1
+	case n > 0:
+		return 1
+	}
+	return 0
+}
+
+func bool_int(b bool) int {
+	if b {
+		return 1
+	}
+	return 0
+}
+
+func is_nan(n MalType) bool {
+	f, ok := n.(float64)
+	return ok && math.IsNaN(f)
+}
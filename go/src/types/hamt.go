@@ -0,0 +1,214 @@
+package types
+
+import (
+	"math/bits"
+)
+
+// Hash maps are persistent: a hash array mapped trie, which takes 5 bits of
+// the Hash of a key at each level. Get, Assoc and Dissoc copy or walk at
+// most one path of the trie, so they take O(log32 n).
+
+const (
+	hamt_bits = 5
+	hamt_mask = 1<<hamt_bits - 1
+)
+
+// hamt_node has a slot for each bit set in bitmap, in the order of the bits
+type hamt_node struct {
+	bitmap uint32
+	slots  []hamt_slot
+}
+
+// hamt_slot is either a subtree or a leaf of the entries whose keys have
+// the same hash, which is more than one entry only when hashes collide
+type hamt_slot struct {
+	node *hamt_node
+	hash uint64
+	ents []MapEntry
+}
+
+func hamt_index(node *hamt_node, hash uint64, shift uint) (uint32, int) {
+	bit := uint32(1) << ((hash >> shift) & hamt_mask)
+	return bit, bits.OnesCount32(node.bitmap & (bit - 1))
+}
+
+func (hm HashMap) Count() int {
+	return hm.count
+}
+
+func (hm HashMap) Get(key MalType) (MalType, bool) {
+	h := Hash(key)
+	node := hm.root
+	for shift := uint(0); node != nil; shift += hamt_bits {
+		bit, idx := hamt_index(node, h, shift)
+		if node.bitmap&bit == 0 {
+			return nil, false
+		}
+		slot := node.slots[idx]
+		if slot.node != nil {
+			node = slot.node
+			continue
+		}
+		if slot.hash == h {
+			for _, ent := range slot.ents {
+				if Equal_Q(ent.Key, key) {
+					return ent.Val, true
+				}
+			}
+		}
+		return nil, false
+	}
+	return nil, false
+}
+
+func (hm HashMap) Assoc(key MalType, val MalType) HashMap {
+	root, added := hamt_assoc(hm.root, 0, Hash(key), key, val)
+	hm.root = root
+	if added {
+		hm.count += 1
+	}
+	return hm
+}
+
+func (hm HashMap) Dissoc(key MalType) HashMap {
+	root, removed := hamt_dissoc(hm.root, 0, Hash(key), key)
+	if removed {
+		hm.root = root
+		hm.count -= 1
+	}
+	return hm
+}
+
+// Entries returns the entries in no particular order
+func (hm HashMap) Entries() []MapEntry {
+	ents := make([]MapEntry, 0, hm.count)
+	var walk func(*hamt_node)
+	walk = func(node *hamt_node) {
+		for _, slot := range node.slots {
+			if slot.node != nil {
+				walk(slot.node)
+			} else {
+				ents = append(ents, slot.ents...)
+			}
+		}
+	}
+	if hm.root != nil {
+		walk(hm.root)
+	}
+	return ents
+}
+
+// hamt_assoc returns a copy of node with the entry, and whether the key is new
+func hamt_assoc(node *hamt_node, shift uint, h uint64, key MalType, val MalType) (*hamt_node, bool) {
+	if node == nil {
+		node = &hamt_node{}
+	}
+	bit, idx := hamt_index(node, h, shift)
+	leaf := hamt_slot{hash: h, ents: []MapEntry{{key, val}}}
+	if node.bitmap&bit == 0 {
+		slots := make([]hamt_slot, 0, len(node.slots)+1)
+		slots = append(slots, node.slots[:idx]...)
+		slots = append(slots, leaf)
+		slots = append(slots, node.slots[idx:]...)
+		return &hamt_node{node.bitmap | bit, slots}, true
+	}
+	slot := node.slots[idx]
+	added := true
+	switch {
+	case slot.node != nil:
+		var child *hamt_node
+		child, added = hamt_assoc(slot.node, shift+hamt_bits, h, key, val)
+		slot = hamt_slot{node: child}
+	case slot.hash == h:
+		ents := append([]MapEntry{}, slot.ents...)
+		for i, ent := range ents {
+			if Equal_Q(ent.Key, key) {
+				ents[i] = MapEntry{ent.Key, val}
+				added = false
+				break
+			}
+		}
+		if added {
+			ents = append(ents, MapEntry{key, val})
+		}
+		slot = hamt_slot{hash: h, ents: ents}
+	default:
+		slot = hamt_slot{node: hamt_merge(shift+hamt_bits, slot, leaf)}
+	}
+	slots := append([]hamt_slot{}, node.slots...)
+	slots[idx] = slot
+	return &hamt_node{node.bitmap, slots}, added
+}
+
+// hamt_merge makes a node of two leaves with different hashes
+func hamt_merge(shift uint, a hamt_slot, b hamt_slot) *hamt_node {
+	ia := (a.hash >> shift) & hamt_mask
+	ib := (b.hash >> shift) & hamt_mask
+	switch {
+	case ia == ib:
+		return &hamt_node{1 << ia, []hamt_slot{{node: hamt_merge(shift+hamt_bits, a, b)}}}
+	case ia < ib:
+		return &hamt_node{1<<ia | 1<<ib, []hamt_slot{a, b}}
+	default:
+		return &hamt_node{1<<ia | 1<<ib, []hamt_slot{b, a}}
+	}
+}
+
+// hamt_dissoc returns a copy of node without key, or nil if it would be
+// empty, and whether key was found
+func hamt_dissoc(node *hamt_node, shift uint, h uint64, key MalType) (*hamt_node, bool) {
+	if node == nil {
+		return nil, false
+	}
+	bit, idx := hamt_index(node, h, shift)
+	if node.bitmap&bit == 0 {
+		return node, false
+	}
+	slot := node.slots[idx]
+	if slot.node != nil {
+		child, removed := hamt_dissoc(slot.node, shift+hamt_bits, h, key)
+		if !removed {
+			return node, false
+		}
+		switch {
+		case child == nil:
+			return hamt_remove_slot(node, bit, idx), true
+		case len(child.slots) == 1 && child.slots[0].node == nil:
+			// a lone leaf moves up, so that the trie stays the same shape
+			// whatever order the entries were added and removed in
+			slot = child.slots[0]
+		default:
+			slot = hamt_slot{node: child}
+		}
+	} else {
+		if slot.hash != h {
+			return node, false
+		}
+		ents := []MapEntry{}
+		for _, ent := range slot.ents {
+			if !Equal_Q(ent.Key, key) {
+				ents = append(ents, ent)
+			}
+		}
+		if len(ents) == len(slot.ents) {
+			return node, false
+		}
+		if len(ents) == 0 {
+			return hamt_remove_slot(node, bit, idx), true
+		}
+		slot = hamt_slot{hash: h, ents: ents}
+	}
+	slots := append([]hamt_slot{}, node.slots...)
+	slots[idx] = slot
+	return &hamt_node{node.bitmap, slots}, true
+}
+
+func hamt_remove_slot(node *hamt_node, bit uint32, idx int) *hamt_node {
+	if node.bitmap == bit {
+		return nil
+	}
+	slots := make([]hamt_slot, 0, len(node.slots)-1)
+	slots = append(slots, node.slots[:idx]...)
+	slots = append(slots, node.slots[idx+1:]...)
+	return &hamt_node{node.bitmap &^ bit, slots}
+}
@@ -0,0 +1,101 @@
+package types
+
+import (
+	"hash/fnv"
+	"math"
+	"math/big"
+	"reflect"
+)
+
+// Hash returns a structural hash of obj. Values which are Equal_Q have the
+// same hash, so a list and a vector with the same elements hash the same.
+
+func Hash(obj MalType) uint64 {
+	switch tobj := obj.(type) {
+	case nil:
+		return 0
+	case bool:
+		if tobj {
+			return 1
+		}
+		return 2
+	case int:
+		return mix(uint64(tobj))
+	case *big.Int:
+		return hash_string(3, tobj.String())
+	case *big.Rat:
+		return hash_string(4, tobj.RatString())
+	case float64:
+		if tobj == 0 {
+			tobj = 0 // -0.0 is equal to 0.0
+		}
+		return mix(math.Float64bits(tobj))
+	case string:
+		return hash_string(5, tobj)
+	case Symbol:
+		return hash_string(6, tobj.Val)
+	case Keyword:
+		return tobj.hash
+	case Regex:
+		return hash_string(12, tobj.String())
+	case List:
+		return hash_seq(tobj.Slice())
+	case Vector:
+		return hash_seq(tobj.Slice())
+	case HashMap:
+		return hash_entries(tobj.Entries())
+	case SortedMap:
+		return hash_entries(tobj.Entries())
+	case HashSet:
+		return hash_set(tobj.Slice())
+	case SortedSet:
+		return hash_set(tobj.Slice())
+	case *Atom:
+		return mix(uint64(reflect.ValueOf(tobj).Pointer()))
+	default:
+		return hash_string(8, reflect.TypeOf(obj).String())
+	}
+}
+
+func hash_seq(seq []MalType) uint64 {
+	h := uint64(9)
+	for _, x := range seq {
+		h = h*31 + Hash(x)
+	}
+	return mix(h)
+}
+
+// the entries of a map or the elements of a set are summed, so that the
+// hash doesn't depend on their order
+func hash_entries(ents []MapEntry) uint64 {
+	h := uint64(7)
+	for _, ent := range ents {
+		h += mix(Hash(ent.Key)*31 + Hash(ent.Val))
+	}
+	return h
+}
+
+func hash_set(slc []MalType) uint64 {
+	h := uint64(10)
+	for _, x := range slc {
+		h += mix(Hash(x))
+	}
+	return h
+}
+
+func hash_string(seed byte, s string) uint64 {
+	h := fnv.New64a()
+	h.Write([]byte{seed})
+	h.Write([]byte(s))
+	return h.Sum64()
+}
+
+// mix scrambles the bits of h, so that small ints spread over the table
+func mix(h uint64) uint64 {
+	h ^= h >> 33
+	h *= 0xff51afd7ed558ccd
+	h ^= h >> 33
+	h *= 0xc4ceb9fe1a85ec53
+	h ^= h >> 33
+	return h
+}
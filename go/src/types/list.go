@@ -0,0 +1,70 @@
+package types
+
+// cons_cell is a List in the cons form. rest is in either form, so consing
+// onto a slice shares the slice.
+type cons_cell struct {
+	first MalType
+	rest  List
+	count int
+}
+
+// Cons returns a list of x followed by the elements of l
+func Cons(x MalType, l List) List {
+	l.Meta = nil
+	return List{cell: &cons_cell{x, l, l.Count() + 1}}
+}
+
+func (l List) Count() int {
+	if l.cell != nil {
+		return l.cell.count
+	}
+	return len(l.Val)
+}
+
+// First returns the first element, or nil if l is empty
+func (l List) First() MalType {
+	if l.cell != nil {
+		return l.cell.first
+	}
+	if len(l.Val) == 0 {
+		return nil
+	}
+	return l.Val[0]
+}
+
+// Rest returns l without the first element, or l if it is empty
+func (l List) Rest() List {
+	if l.cell != nil {
+		return l.cell.rest
+	}
+	if len(l.Val) == 0 {
+		return List{}
+	}
+	return List{Val: l.Val[1:]}
+}
+
+// Nth returns the element at i, which must be in range
+func (l List) Nth(i int) MalType {
+	for l.cell != nil {
+		if i == 0 {
+			return l.cell.first
+		}
+		l = l.cell.rest
+		i -= 1
+	}
+	return l.Val[i]
+}
+
+// Slice returns the elements. It is Val in the slice form, so it must not
+// be modified.
+func (l List) Slice() []MalType {
+	if l.cell == nil {
+		return l.Val
+	}
+	slc := make([]MalType, 0, l.cell.count)
+	for l.cell != nil {
+		slc = append(slc, l.cell.first)
+		l = l.cell.rest
+	}
+	return append(slc, l.Val...)
+}
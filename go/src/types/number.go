@@ -0,0 +1,252 @@
+package types
+
+import (
+	"errors"
+	"math"
+	"math/big"
+)
+
+// Numbers are int, *big.Int, float64 or *big.Rat. A *big.Int is always
+// an integer too large for an int, and a *big.Rat is always an exact ratio
+// whose denominator isn't 1, so that every number has one representation.
+
+func Number_Q(obj MalType) bool {
+	switch obj.(type) {
+	case int, *big.Int, float64, *big.Rat:
+		return true
+	}
+	return false
+}
+
+// NewBigInt makes a number from i, an int if i fits in one
+func NewBigInt(i *big.Int) MalType {
+	if i.IsInt64() {
+		n := i.Int64()
+		if int64(int(n)) == n {
+			return int(n)
+		}
+	}
+	return i
+}
+
+// NewRatio makes a number from r, an integer if r is an integer
+func NewRatio(r *big.Rat) MalType {
+	if r.IsInt() {
+		return NewBigInt(r.Num())
+	}
+	return r
+}
+
+// Contagion: an operation on numbers of different kinds is done in the
+// wider kind, int < big int < ratio < float. An operation on ints which
+// overflows is done again on big ints.
+const (
+	kindInt = iota
+	kindBigInt
+	kindRatio
+	kindFloat
+)
+
+func num_kind(name string, n MalType) (int, error) {
+	switch n.(type) {
+	case int:
+		return kindInt, nil
+	case *big.Int:
+		return kindBigInt, nil
+	case *big.Rat:
+		return kindRatio, nil
+	case float64:
+		return kindFloat, nil
+	}
+	return 0, errors.New(name + " called with non-number")
+}
+
+func to_big_int(n MalType) *big.Int {
+	switch t := n.(type) {
+	case int:
+		return big.NewInt(int64(t))
+	case *big.Int:
+		return t
+	}
+	panic("to_big_int called with non-integer")
+}
+
+func to_rat(n MalType) *big.Rat {
+	switch t := n.(type) {
+	case int:
+		return new(big.Rat).SetInt64(int64(t))
+	case *big.Int:
+		return new(big.Rat).SetInt(t)
+	case *big.Rat:
+		return t
+	}
+	panic("to_rat called with non-exact number")
+}
+
+func to_float(n MalType) float64 {
+	switch t := n.(type) {
+	case int:
+		return float64(t)
+	case *big.Int:
+		f, _ := new(big.Float).SetInt(t).Float64()
+		return f
+	case *big.Rat:
+		f, _ := t.Float64()
+		return f
+	case float64:
+		return t
+	}
+	panic("to_float called with non-number")
+}
+
+// arith applies the operation for the wider kind of a and b. fi reports
+// whether its result fits in an int; if it doesn't, fb is used instead.
+func arith(name string, a MalType, b MalType,
+	fi func(int, int) (int, bool),
+	fb func(*big.Int, *big.Int) MalType,
+	fr func(*big.Rat, *big.Rat) *big.Rat,
+	ff func(float64, float64) float64) (MalType, error) {
+	ka, e := num_kind(name, a)
+	if e != nil {
+		return nil, e
+	}
+	kb, e := num_kind(name, b)
+	if e != nil {
+		return nil, e
+	}
+	switch {
+	case ka == kindFloat || kb == kindFloat:
+		return ff(to_float(a), to_float(b)), nil
+	case ka == kindRatio || kb == kindRatio:
+		return NewRatio(fr(to_rat(a), to_rat(b))), nil
+	case ka == kindInt && kb == kindInt:
+		if n, ok := fi(a.(int), b.(int)); ok {
+			return n, nil
+		}
+	}
+	return fb(to_big_int(a), to_big_int(b)), nil
+}
+
+func Add(a MalType, b MalType) (MalType, error) {
+	return arith("+", a, b,
+		func(x, y int) (int, bool) {
+			s := x + y
+			return s, (x^s)&(y^s) >= 0
+		},
+		func(x, y *big.Int) MalType { return NewBigInt(new(big.Int).Add(x, y)) },
+		func(x, y *big.Rat) *big.Rat { return new(big.Rat).Add(x, y) },
+		func(x, y float64) float64 { return x + y })
+}
+
+func Sub(a MalType, b MalType) (MalType, error) {
+	return arith("-", a, b,
+		func(x, y int) (int, bool) {
+			d := x - y
+			return d, (x^y)&(x^d) >= 0
+		},
+		func(x, y *big.Int) MalType { return NewBigInt(new(big.Int).Sub(x, y)) },
+		func(x, y *big.Rat) *big.Rat { return new(big.Rat).Sub(x, y) },
+		func(x, y float64) float64 { return x - y })
+}
+
+// Neg negates a, keeping the sign of a float zero, so (- 0.0) is -0.0
+func Neg(a MalType) (MalType, error) {
+	switch n := a.(type) {
+	case int:
+		if n == math.MinInt {
+			return NewBigInt(new(big.Int).Neg(big.NewInt(int64(n)))), nil
+		}
+		return -n, nil
+	case *big.Int:
+		return NewBigInt(new(big.Int).Neg(n)), nil
+	case *big.Rat:
+		return new(big.Rat).Neg(n), nil
+	case float64:
+		return -n, nil
+	}
+	return nil, errors.New("- called with non-number")
+}
+
+func Mul(a MalType, b MalType) (MalType, error) {
+	return arith("*", a, b,
+		func(x, y int) (int, bool) {
+			if x == 0 || y == 0 {
+				return 0, true
+			}
+			p := x * y
+			return p, p/y == x && !(x == -1 && y == math.MinInt) &&
+				!(y == -1 && x == math.MinInt)
+		},
+		func(x, y *big.Int) MalType { return NewBigInt(new(big.Int).Mul(x, y)) },
+		func(x, y *big.Rat) *big.Rat { return new(big.Rat).Mul(x, y) },
+		func(x, y float64) float64 { return x * y })
+}
+
+// ErrDivisionByZero is the error of Div with a zero divisor
+var ErrDivisionByZero = errors.New("division by zero")
+
+// Div divides exactly: the quotient of integers is a ratio unless it is an integer
+func Div(a MalType, b MalType) (MalType, error) {
+	if is_zero(b) {
+		return nil, ErrDivisionByZero
+	}
+	return arith("/", a, b,
+		func(x, y int) (int, bool) {
+			return x / y, x%y == 0 && !(x == math.MinInt && y == -1)
+		},
+		func(x, y *big.Int) MalType { return NewRatio(new(big.Rat).SetFrac(x, y)) },
+		func(x, y *big.Rat) *big.Rat { return new(big.Rat).Quo(x, y) },
+		func(x, y float64) float64 { return x / y })
+}
+
+// is_zero tests an exact number for being exactly zero, so that a tiny
+// ratio or big integer isn't rounded to 0.0
+func is_zero(n MalType) bool {
+	switch tn := n.(type) {
+	case int:
+		return tn == 0
+	case *big.Int:
+		return tn.Sign() == 0
+	case *big.Rat:
+		return tn.Sign() == 0
+	case float64:
+		return tn == 0
+	}
+	return false
+}
+
+// NumCmp returns -1, 0 or 1 as a is less than, equal to or greater than b.
+// NaN is neither less than, equal to nor greater than any number, so
+// comparing it returns 2.
+func NumCmp(name string, a MalType, b MalType) (int, error) {
+	c, e := arith(name, a, b,
+		func(x, y int) (int, bool) {
+			switch {
+			case x < y:
+				return -1, true
+			case x > y:
+				return 1, true
+			}
+			return 0, true
+		},
+		func(x, y *big.Int) MalType { return x.Cmp(y) },
+		func(x, y *big.Rat) *big.Rat { return big.NewRat(int64(x.Cmp(y)), 1) },
+		func(x, y float64) float64 {
+			switch {
+			case math.IsNaN(x) || math.IsNaN(y):
+				return 2
+			case x < y:
+				return -1
+			case x > y:
+				return 1
+			}
+			return 0
+		})
+	if e != nil {
+		return 0, e
+	}
+	if f, ok := c.(float64); ok {
+		return int(f), nil
+	}
+	return c.(int), nil
+}
@@ -0,0 +1,37 @@
+package types
+
+import (
+	"regexp"
+)
+
+// Regex is a compiled regular expression, read as #"pattern". Two
+// regexes with the same pattern are equal.
+type Regex struct {
+	*regexp.Regexp
+	whole *regexp.Regexp
+}
+
+func NewRegex(pattern string) (MalType, error) {
+	re, e := regexp.Compile(pattern)
+	if e != nil {
+		return nil, e
+	}
+	// the pattern is in a group of its own, so that an alternation in it
+	// is anchored as a whole
+	whole, e := regexp.Compile(`^(?:` + pattern + `)$`)
+	if e != nil {
+		return nil, e
+	}
+	return Regex{re, whole}, nil
+}
+
+func Regex_Q(obj MalType) bool {
+	_, ok := obj.(Regex)
+	return ok
+}
+
+// FindWholeStringSubmatchIndex is like FindStringSubmatchIndex, but only
+// matches the whole of s
+func (r Regex) FindWholeStringSubmatchIndex(s string) []int {
+	return r.whole.FindStringSubmatchIndex(s)
+}
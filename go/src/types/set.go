@@ -0,0 +1,74 @@
+package types
+
+// Hash Sets
+
+// HashSet is immutable: Conj and Disj return a new set. It is a HashMap
+// whose keys are the elements, each mapped to itself.
+type HashSet struct {
+	m    HashMap
+	Meta MalType
+}
+
+func NewHashSet(a ...MalType) HashSet {
+	hs := HashSet{}
+	for _, x := range a {
+		hs = hs.Conj(x)
+	}
+	return hs
+}
+
+func HashSet_Q(obj MalType) bool {
+	_, ok := obj.(HashSet)
+	return ok
+}
+
+// Set_Q is true for both hash sets and sorted sets
+func Set_Q(obj MalType) bool {
+	return HashSet_Q(obj) || SortedSet_Q(obj)
+}
+
+func (hs HashSet) Count() int {
+	return hs.m.Count()
+}
+
+func (hs HashSet) Contains(x MalType) bool {
+	_, ok := hs.m.Get(x)
+	return ok
+}
+
+func (hs HashSet) Conj(x MalType) HashSet {
+	if !hs.Contains(x) {
+		hs.m = hs.m.Assoc(x, x)
+	}
+	return hs
+}
+
+func (hs HashSet) Disj(x MalType) HashSet {
+	hs.m = hs.m.Dissoc(x)
+	return hs
+}
+
+// Slice returns the elements in no particular order
+func (hs HashSet) Slice() []MalType {
+	slc := make([]MalType, 0, hs.Count())
+	for _, ent := range hs.m.Entries() {
+		slc = append(slc, ent.Key)
+	}
+	return slc
+}
+
+// set_elems and set_contains treat hash sets and sorted sets alike
+func set_elems(obj MalType) []MalType {
+	if ss, ok := obj.(SortedSet); ok {
+		return ss.Slice()
+	}
+	return obj.(HashSet).Slice()
+}
+
+func set_contains(obj MalType, x MalType) bool {
+	if ss, ok := obj.(SortedSet); ok {
+		ok, _ := ss.Contains(x)
+		return ok
+	}
+	return obj.(HashSet).Contains(x)
+}
@@ -0,0 +1,313 @@
+package types
+
+import (
+	"errors"
+)
+
+// Sorted maps and sets are persistent AVL trees, ordered by Compare or by
+// the comparator given to sorted-map-by. Assoc, Dissoc and Get take
+// O(log n), and Entries are in order.
+
+type Comparator func(MalType, MalType) (int, error)
+
+type SortedMap struct {
+	root  *sorted_node
+	count int
+	cmp   Comparator
+	Meta  MalType
+}
+
+type sorted_node struct {
+	key    MalType
+	val    MalType
+	left   *sorted_node
+	right  *sorted_node
+	height int
+}
+
+func NewSortedMap(cmp Comparator) SortedMap {
+	if cmp == nil {
+		cmp = Compare
+	}
+	return SortedMap{cmp: cmp}
+}
+
+func SortedMap_Q(obj MalType) bool {
+	_, ok := obj.(SortedMap)
+	return ok
+}
+
+// NewFnComparator makes a Comparator of a mal function, which returns
+// either a number or, like <, whether its first argument comes first
+func NewFnComparator(f MalType) Comparator {
+	return func(a MalType, b MalType) (int, error) {
+		res, e := Apply(f, []MalType{a, b})
+		if e != nil {
+			return 0, e
+		}
+		if Number_Q(res) {
+			return NumCmp("comparator", res, 0)
+		}
+		less, e := comparator_bool(res)
+		if e != nil {
+			return 0, e
+		}
+		if less {
+			return -1, nil
+		}
+		if res, e = Apply(f, []MalType{b, a}); e != nil {
+			return 0, e
+		}
+		greater, e := comparator_bool(res)
+		if e != nil {
+			return 0, e
+		}
+		if greater {
+			return 1, nil
+		}
+		return 0, nil
+	}
+}
+
+func comparator_bool(res MalType) (bool, error) {
+	b, ok := res.(bool)
+	if !ok {
+		return false, errors.New("comparator must return a number or boolean")
+	}
+	return b, nil
+}
+
+func (sm SortedMap) Count() int {
+	return sm.count
+}
+
+func (sm SortedMap) Get(key MalType) (MalType, bool, error) {
+	node := sm.root
+	for node != nil {
+		c, e := sm.cmp(key, node.key)
+		switch {
+		case e != nil:
+			return nil, false, e
+		case c < 0:
+			node = node.left
+		case c > 0:
+			node = node.right
+		default:
+			return node.val, true, nil
+		}
+	}
+	return nil, false, nil
+}
+
+func (sm SortedMap) Assoc(key MalType, val MalType) (SortedMap, error) {
+	root, added, e := sm.insert(sm.root, key, val)
+	if e != nil {
+		return sm, e
+	}
+	sm.root = root
+	if added {
+		sm.count += 1
+	}
+	return sm, nil
+}
+
+func (sm SortedMap) Dissoc(key MalType) (SortedMap, error) {
+	root, removed, e := sm.remove(sm.root, key)
+	if e != nil || !removed {
+		return sm, e
+	}
+	sm.root = root
+	sm.count -= 1
+	return sm, nil
+}
+
+// Entries returns the entries in order
+func (sm SortedMap) Entries() []MapEntry {
+	ents := make([]MapEntry, 0, sm.count)
+	var walk func(*sorted_node)
+	walk = func(node *sorted_node) {
+		if node != nil {
+			walk(node.left)
+			ents = append(ents, MapEntry{node.key, node.val})
+			walk(node.right)
+		}
+	}
+	walk(sm.root)
+	return ents
+}
+
+// Compare compares keys in the order of sm
+func (sm SortedMap) Compare(a MalType, b MalType) (int, error) {
+	return sm.cmp(a, b)
+}
+
+func (sm SortedMap) insert(node *sorted_node, key MalType, val MalType) (*sorted_node, bool, error) {
+	if node == nil {
+		return &sorted_node{key, val, nil, nil, 1}, true, nil
+	}
+	c, e := sm.cmp(key, node.key)
+	if e != nil {
+		return nil, false, e
+	}
+	n := *node
+	added := false
+	switch {
+	case c < 0:
+		n.left, added, e = sm.insert(node.left, key, val)
+	case c > 0:
+		n.right, added, e = sm.insert(node.right, key, val)
+	default:
+		n.val = val
+	}
+	if e != nil {
+		return nil, false, e
+	}
+	return rebalance(&n), added, nil
+}
+
+func (sm SortedMap) remove(node *sorted_node, key MalType) (*sorted_node, bool, error) {
+	if node == nil {
+		return nil, false, nil
+	}
+	c, e := sm.cmp(key, node.key)
+	if e != nil {
+		return nil, false, e
+	}
+	n := *node
+	removed := false
+	switch {
+	case c < 0:
+		n.left, removed, e = sm.remove(node.left, key)
+	case c > 0:
+		n.right, removed, e = sm.remove(node.right, key)
+	default:
+		if node.left == nil {
+			return node.right, true, nil
+		}
+		if node.right == nil {
+			return node.left, true, nil
+		}
+		// the next entry takes the place of node
+		next := node.right
+		for next.left != nil {
+			next = next.left
+		}
+		n.key, n.val = next.key, next.val
+		n.right = remove_min(node.right)
+		removed = true
+	}
+	if e != nil || !removed {
+		return node, false, e
+	}
+	return rebalance(&n), true, nil
+}
+
+func remove_min(node *sorted_node) *sorted_node {
+	if node.left == nil {
+		return node.right
+	}
+	n := *node
+	n.left = remove_min(node.left)
+	return rebalance(&n)
+}
+
+func height(node *sorted_node) int {
+	if node == nil {
+		return 0
+	}
+	return node.height
+}
+
+func fix_height(node *sorted_node) *sorted_node {
+	node.height = height(node.left) + 1
+	if h := height(node.right) + 1; h > node.height {
+		node.height = h
+	}
+	return node
+}
+
+// rebalance fixes node, a new node whose kids differ in height by at most 2
+func rebalance(node *sorted_node) *sorted_node {
+	fix_height(node)
+	switch balance := height(node.left) - height(node.right); {
+	case balance > 1:
+		if height(node.left.left) < height(node.left.right) {
+			node.left = rotate_left(node.left)
+		}
+		return rotate_right(node)
+	case balance < -1:
+		if height(node.right.right) < height(node.right.left) {
+			node.right = rotate_right(node.right)
+		}
+		return rotate_left(node)
+	}
+	return node
+}
+
+func rotate_left(node *sorted_node) *sorted_node {
+	r := *node.right
+	n := *node
+	n.right = r.left
+	r.left = fix_height(&n)
+	return fix_height(&r)
+}
+
+func rotate_right(node *sorted_node) *sorted_node {
+	l := *node.left
+	n := *node
+	n.left = l.right
+	l.right = fix_height(&n)
+	return fix_height(&l)
+}
+
+// Sorted sets
+type SortedSet struct {
+	m    SortedMap
+	Meta MalType
+}
+
+func NewSortedSet(cmp Comparator) SortedSet {
+	return SortedSet{m: NewSortedMap(cmp)}
+}
+
+func SortedSet_Q(obj MalType) bool {
+	_, ok := obj.(SortedSet)
+	return ok
+}
+
+func (ss SortedSet) Count() int {
+	return ss.m.Count()
+}
+
+func (ss SortedSet) Contains(x MalType) (bool, error) {
+	_, ok, e := ss.m.Get(x)
+	return ok, e
+}
+
+func (ss SortedSet) Conj(x MalType) (SortedSet, error) {
+	if ok, e := ss.Contains(x); e != nil || ok {
+		return ss, e
+	}
+	m, e := ss.m.Assoc(x, x)
+	ss.m = m
+	return ss, e
+}
+
+func (ss SortedSet) Disj(x MalType) (SortedSet, error) {
+	m, e := ss.m.Dissoc(x)
+	ss.m = m
+	return ss, e
+}
+
+// Slice returns the elements in order
+func (ss SortedSet) Slice() []MalType {
+	slc := make([]MalType, 0, ss.Count())
+	for _, ent := range ss.m.Entries() {
+		slc = append(slc, ent.Key)
+	}
+	return slc
+}
+
+func (ss SortedSet) Compare(a MalType, b MalType) (int, error) {
+	return ss.m.Compare(a, b)
+}
@@ -3,8 +3,10 @@ package types
 import (
 	"errors"
 	"fmt"
+	"math/big"
 	"reflect"
 	"strings"
+	"sync"
 )
 
 // Errors/Exceptions
@@ -41,11 +43,6 @@ func False_Q(obj MalType) bool {
 	return ok && b == false
 }
 
-func Number_Q(obj MalType) bool {
-	_, ok := obj.(int)
-	return ok
-}
-
 // Symbols
 type Symbol struct {
 	Val string
@@ -56,14 +53,80 @@ func Symbol_Q(obj MalType) bool {
 	return ok
 }
 
+func (s Symbol) Namespace() string {
+	ns, _ := split_name(s.Val)
+	return ns
+}
+
+func (s Symbol) Name() string {
+	_, name := split_name(s.Val)
+	return name
+}
+
+// split_name splits "ns/name" into its namespace and name. A name without
+// a namespace, including "/" itself, has the namespace "".
+func split_name(s string) (string, string) {
+	if i := strings.Index(s, "/"); i > 0 && i < len(s)-1 {
+		return s[:i], s[i+1:]
+	}
+	return "", s
+}
+
 // Keywords
+
+// Keyword is interned: there is one keyword for each name, so keywords
+// are equal exactly when they are == in Go, which takes constant time
+type Keyword struct {
+	*keyword
+}
+
+type keyword struct {
+	ns   string
+	name string
+	hash uint64
+}
+
+var keywords = struct {
+	sync.Mutex
+	m map[string]Keyword
+}{m: map[string]Keyword{}}
+
+// NewKeyword returns the keyword named s, which is "ns/name" for a
+// keyword in a namespace
 func NewKeyword(s string) (MalType, error) {
-	return "\u029e" + s, nil
+	if s == "" {
+		return nil, errors.New("keyword name is empty")
+	}
+	keywords.Lock()
+	defer keywords.Unlock()
+	if k, ok := keywords.m[s]; ok {
+		return k, nil
+	}
+	ns, name := split_name(s)
+	k := Keyword{&keyword{ns, name, hash_string(11, s)}}
+	keywords.m[s] = k
+	return k, nil
 }
 
 func Keyword_Q(obj MalType) bool {
-	s, ok := obj.(string)
-	return ok && strings.HasPrefix(s, "\u029e")
+	_, ok := obj.(Keyword)
+	return ok
+}
+
+func (k Keyword) Namespace() string {
+	return k.ns
+}
+
+func (k Keyword) Name() string {
+	return k.name
+}
+
+// String returns the keyword as it is read and printed, like :ns/name
+func (k Keyword) String() string {
+	if k.ns == "" {
+		return ":" + k.name
+	}
+	return ":" + k.ns + "/" + k.name
 }
 
 // Strings
@@ -112,7 +175,7 @@ func (f MalFunc) GetMacro() bool {
 func Apply(f_mt MalType, a []MalType) (MalType, error) {
 	switch f := f_mt.(type) {
 	case MalFunc:
-		env, e := f.GenEnv(f.Env, f.Params, List{a, nil})
+		env, e := f.GenEnv(f.Env, f.Params, List{Val: a})
 		if e != nil {
 			return nil, e
 		}
@@ -127,13 +190,17 @@ func Apply(f_mt MalType, a []MalType) (MalType, error) {
 }
 
 // Lists
+
+// List is either a slice, Val, or a chain of cons cells, which Cons and
+// Rest make in O(1). Slice returns the elements in either form.
 type List struct {
 	Val  []MalType
 	Meta MalType
+	cell *cons_cell
 }
 
 func NewList(a ...MalType) MalType {
-	return List{a, nil}
+	return List{Val: a}
 }
 
 func List_Q(obj MalType) bool {
@@ -142,9 +209,15 @@ func List_Q(obj MalType) bool {
 }
 
 // Vectors
+
+// Vector is immutable: Conj and Assoc return a new vector, which shares
+// most of its trie with the old one
 type Vector struct {
-	Val  []MalType
-	Meta MalType
+	count int
+	shift uint
+	root  *vec_node
+	tail  []MalType
+	Meta  MalType
 }
 
 func Vector_Q(obj MalType) bool {
@@ -155,18 +228,26 @@ func Vector_Q(obj MalType) bool {
 func GetSlice(seq MalType) ([]MalType, error) {
 	switch obj := seq.(type) {
 	case List:
-		return obj.Val, nil
+		return obj.Slice(), nil
 	case Vector:
-		return obj.Val, nil
+		return obj.Slice(), nil
 	default:
 		return nil, errors.New("GetSlice called on non-sequence")
 	}
 }
 
 // Hash Maps
+type MapEntry struct {
+	Key MalType
+	Val MalType
+}
+
+// HashMap is immutable: Assoc and Dissoc return a new map, which shares
+// most of its trie with the old one
 type HashMap struct {
-	Val  map[string]MalType
-	Meta MalType
+	root  *hamt_node
+	count int
+	Meta  MalType
 }
 
 func NewHashMap(seq MalType) (MalType, error) {
@@ -177,15 +258,11 @@ func NewHashMap(seq MalType) (MalType, error) {
 	if len(lst)%2 == 1 {
 		return nil, errors.New("Odd number of arguments to NewHashMap")
 	}
-	m := map[string]MalType{}
+	hm := HashMap{}
 	for i := 0; i < len(lst); i += 2 {
-		str, ok := lst[i].(string)
-		if !ok {
-			return nil, errors.New("expected hash-map key string")
-		}
-		m[str] = lst[i+1]
+		hm = hm.Assoc(lst[i], lst[i+1])
 	}
-	return HashMap{m, nil}, nil
+	return hm, nil
 }
 
 func HashMap_Q(obj MalType) bool {
@@ -193,6 +270,26 @@ func HashMap_Q(obj MalType) bool {
 	return ok
 }
 
+// a hash-map and a sorted-map with the same entries are equal
+func map_Q(obj MalType) bool {
+	return HashMap_Q(obj) || SortedMap_Q(obj)
+}
+
+func map_entries(obj MalType) []MapEntry {
+	if sm, ok := obj.(SortedMap); ok {
+		return sm.Entries()
+	}
+	return obj.(HashMap).Entries()
+}
+
+func map_get(obj MalType, key MalType) (MalType, bool) {
+	if sm, ok := obj.(SortedMap); ok {
+		v, ok, _ := sm.Get(key)
+		return v, ok
+	}
+	return obj.(HashMap).Get(key)
+}
+
 // Atoms
 type Atom struct {
 	Val  MalType
@@ -229,7 +326,8 @@ func Sequential_Q(seq MalType) bool {
 func Equal_Q(a MalType, b MalType) bool {
 	ota := reflect.TypeOf(a)
 	otb := reflect.TypeOf(b)
-	if !((ota == otb) || (Sequential_Q(a) && Sequential_Q(b))) {
+	if !((ota == otb) || (Sequential_Q(a) && Sequential_Q(b)) ||
+		(map_Q(a) && map_Q(b)) || (Set_Q(a) && Set_Q(b))) {
 		return false
 	}
 	//av := reflect.ValueOf(a); bv := reflect.ValueOf(b)
@@ -238,6 +336,12 @@ func Equal_Q(a MalType, b MalType) bool {
 	switch a.(type) {
 	case Symbol:
 		return a.(Symbol).Val == b.(Symbol).Val
+	case *big.Int:
+		return a.(*big.Int).Cmp(b.(*big.Int)) == 0
+	case *big.Rat:
+		return a.(*big.Rat).Cmp(b.(*big.Rat)) == 0
+	case Regex:
+		return a.(Regex).String() == b.(Regex).String()
 	case List:
 		as, _ := GetSlice(a)
 		bs, _ := GetSlice(b)
@@ -262,19 +366,34 @@ func Equal_Q(a MalType, b MalType) bool {
 			}
 		}
 		return true
-	case HashMap:
-		am := a.(HashMap).Val
-		bm := b.(HashMap).Val
-		if len(am) != len(bm) {
+	case HashMap, SortedMap:
+		am := map_entries(a)
+		if len(am) != len(map_entries(b)) {
+			return false
+		}
+		for _, ent := range am {
+			v, ok := map_get(b, ent.Key)
+			if !ok || !Equal_Q(ent.Val, v) {
+				return false
+			}
+		}
+		return true
+	case HashSet, SortedSet:
+		as := set_elems(a)
+		if len(as) != len(set_elems(b)) {
 			return false
 		}
-		for k, v := range am {
-			if !Equal_Q(v, bm[k]) {
+		for _, x := range as {
+			if !set_contains(b, x) {
 				return false
 			}
 		}
 		return true
 	default:
+		if ota != nil && !ota.Comparable() {
+			// functions are never equal, rather than panicking
+			return false
+		}
 		return a == b
 	}
 }
@@ -0,0 +1,142 @@
+package types
+
+// Vectors are persistent: a 32-way trie of the elements but the last few,
+// which are kept in the tail. Conj, Assoc and Nth copy or walk at most one
+// path of the trie, so they take O(log32 n).
+
+const (
+	vec_bits  = 5
+	vec_width = 1 << vec_bits
+	vec_mask  = vec_width - 1
+)
+
+// vec_node is a node of the trie, with either kids or, at the bottom, vals
+type vec_node struct {
+	kids []*vec_node
+	vals []MalType
+}
+
+func NewVector(a ...MalType) Vector {
+	v := Vector{}
+	for _, x := range a {
+		v = v.Conj(x)
+	}
+	return v
+}
+
+func (v Vector) Count() int {
+	return v.count
+}
+
+// Nth returns the element at i, which must be in range
+func (v Vector) Nth(i int) MalType {
+	return v.chunk_for(i)[i&vec_mask]
+}
+
+// Conj returns v with x added at the end
+func (v Vector) Conj(x MalType) Vector {
+	if v.root == nil {
+		v.root = &vec_node{}
+		v.shift = vec_bits
+	}
+	if v.count-v.tail_offset() < vec_width {
+		v.tail = append(v.tail[:len(v.tail):len(v.tail)], x)
+		v.count += 1
+		return v
+	}
+	tail_node := &vec_node{vals: v.tail}
+	if (v.count >> vec_bits) > (1 << v.shift) {
+		// the trie is full, so it grows a level
+		v.root = &vec_node{kids: []*vec_node{v.root, new_path(v.shift, tail_node)}}
+		v.shift += vec_bits
+	} else {
+		v.root = v.push_tail(v.shift, v.root, tail_node)
+	}
+	v.tail = []MalType{x}
+	v.count += 1
+	return v
+}
+
+// Assoc returns v with x at i, which must be in range or the end of v
+func (v Vector) Assoc(i int, x MalType) Vector {
+	if i == v.count {
+		return v.Conj(x)
+	}
+	if i >= v.tail_offset() {
+		tail := append([]MalType{}, v.tail...)
+		tail[i&vec_mask] = x
+		v.tail = tail
+		return v
+	}
+	v.root = assoc_path(v.shift, v.root, i, x)
+	return v
+}
+
+// Slice returns the elements in a new slice
+func (v Vector) Slice() []MalType {
+	slc := make([]MalType, 0, v.count)
+	for i := 0; i < v.count; i += vec_width {
+		slc = append(slc, v.chunk_for(i)...)
+	}
+	return slc
+}
+
+// tail_offset is the index of the first element in the tail
+func (v Vector) tail_offset() int {
+	if v.count < vec_width {
+		return 0
+	}
+	return ((v.count - 1) >> vec_bits) << vec_bits
+}
+
+// chunk_for returns the vals of the bottom node holding i
+func (v Vector) chunk_for(i int) []MalType {
+	if i >= v.tail_offset() {
+		return v.tail
+	}
+	node := v.root
+	for level := v.shift; level > 0; level -= vec_bits {
+		node = node.kids[(i>>level)&vec_mask]
+	}
+	return node.vals
+}
+
+// push_tail returns a copy of parent with the full tail added as the
+// rightmost bottom node
+func (v Vector) push_tail(level uint, parent *vec_node, tail_node *vec_node) *vec_node {
+	sub := ((v.count - 1) >> level) & vec_mask
+	node := &vec_node{kids: append([]*vec_node{}, parent.kids...)}
+	var child *vec_node
+	if level == vec_bits {
+		child = tail_node
+	} else if sub < len(parent.kids) {
+		child = v.push_tail(level-vec_bits, parent.kids[sub], tail_node)
+	} else {
+		child = new_path(level-vec_bits, tail_node)
+	}
+	if sub < len(node.kids) {
+		node.kids[sub] = child
+	} else {
+		node.kids = append(node.kids, child)
+	}
+	return node
+}
+
+func new_path(level uint, node *vec_node) *vec_node {
+	if level == 0 {
+		return node
+	}
+	return &vec_node{kids: []*vec_node{new_path(level-vec_bits, node)}}
+}
+
+func assoc_path(level uint, node *vec_node, i int, x MalType) *vec_node {
+	if level == 0 {
+		vals := append([]MalType{}, node.vals...)
+		vals[i&vec_mask] = x
+		return &vec_node{vals: vals}
+	}
+	kids := append([]*vec_node{}, node.kids...)
+	sub := (i >> level) & vec_mask
+	kids[sub] = assoc_path(level-vec_bits, kids[sub], i, x)
+	return &vec_node{kids: kids}
+}
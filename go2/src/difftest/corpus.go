@@ -0,0 +1,133 @@
+package main
+
+import (
+	"errors"
+	"io/ioutil"
+	"strings"
+)
+
+func readFile(file string) (string, error) {
+	b, err := ioutil.ReadFile(file)
+	return string(b), err
+}
+
+// testForms returns the forms in a test file for runtest.py, which has a form per line
+func testForms(src string) []form {
+	var forms []form
+	for i, line := range strings.Split(src, "\n") {
+		if strings.TrimSpace(line) == "" || strings.HasPrefix(line, ";") {
+			continue
+		}
+		forms = append(forms, form{line: i + 1, src: line})
+	}
+	return forms
+}
+
+// scanner finds where the forms of a mal program start and end
+type scanner struct {
+	s    []rune
+	pos  int
+	line int
+}
+
+// splitForms returns the top level forms in a mal program
+func splitForms(src string) ([]form, error) {
+	sc := &scanner{s: []rune(src), line: 1}
+	var forms []form
+	for {
+		sc.skipBlank()
+		if sc.pos == len(sc.s) {
+			return forms, nil
+		}
+		start, line := sc.pos, sc.line
+		if err := sc.skipForm(); err != nil {
+			return nil, err
+		}
+		forms = append(forms, form{line: line, src: string(sc.s[start:sc.pos])})
+	}
+}
+
+func (sc *scanner) advance() {
+	if sc.s[sc.pos] == '\n' {
+		sc.line++
+	}
+	sc.pos++
+}
+
+// skipBlank skips spaces, commas and comments
+func (sc *scanner) skipBlank() {
+	for sc.pos < len(sc.s) {
+		switch c := sc.s[sc.pos]; {
+		case c == ';':
+			for sc.pos < len(sc.s) && sc.s[sc.pos] != '\n' {
+				sc.advance()
+			}
+		case c == ',' || strings.ContainsRune(" \t\r\n", c):
+			sc.advance()
+		default:
+			return
+		}
+	}
+}
+
+// skipForm skips a form starting at the current position
+func (sc *scanner) skipForm() error {
+	sc.skipBlank()
+	if sc.pos == len(sc.s) {
+		return errors.New("unexpected EOF")
+	}
+	switch c := sc.s[sc.pos]; c {
+	case '(', '[', '{':
+		right := map[rune]rune{'(': ')', '[': ']', '{': '}'}[c]
+		sc.advance()
+		for {
+			sc.skipBlank()
+			if sc.pos == len(sc.s) {
+				return errors.New("expected '" + string(right) + "', got EOF")
+			}
+			if sc.s[sc.pos] == right {
+				sc.advance()
+				return nil
+			}
+			if err := sc.skipForm(); err != nil {
+				return err
+			}
+		}
+	case ')', ']', '}':
+		return errors.New("unexpected '" + string(c) + "'")
+	case '\'', '`', '@':
+		sc.advance()
+		return sc.skipForm()
+	case '~':
+		sc.advance()
+		if sc.pos < len(sc.s) && sc.s[sc.pos] == '@' {
+			sc.advance()
+		}
+		return sc.skipForm()
+	case '^': // ^META FORM
+		sc.advance()
+		if err := sc.skipForm(); err != nil {
+			return err
+		}
+		return sc.skipForm()
+	case '"':
+		sc.advance()
+		for sc.pos < len(sc.s) && sc.s[sc.pos] != '"' {
+			if sc.s[sc.pos] == '\\' {
+				sc.advance()
+			}
+			if sc.pos < len(sc.s) {
+				sc.advance()
+			}
+		}
+		if sc.pos == len(sc.s) {
+			return errors.New("expected '\"', got EOF")
+		}
+		sc.advance()
+		return nil
+	}
+	for sc.pos < len(sc.s) && !strings.ContainsRune(" \t\r\n,;()[]{}\"'`~@^", sc.s[sc.pos]) {
+		sc.advance()
+	}
+	return nil
+}
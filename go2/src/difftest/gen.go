@@ -0,0 +1,166 @@
+package main
+
+import (
+	"fmt"
+	"math/rand"
+	"strings"
+)
+
+// generator makes random expressions. They are mostly well typed,
+// but sometimes an argument of any type is used to compare the errors.
+type generator struct {
+	r *rand.Rand
+}
+
+// randomCorpus makes n independent random expressions
+func randomCorpus(seed int64, n int) corpus {
+	g := &generator{r: rand.New(rand.NewSource(seed))}
+	forms := make([]form, n)
+	for i := range forms {
+		forms[i] = form{line: i + 1, src: g.any(4)}
+	}
+	return corpus{name: fmt.Sprintf("random(seed=%d)", seed), forms: forms}
+}
+
+func (g *generator) pick(choices ...string) string {
+	return choices[g.r.Intn(len(choices))]
+}
+
+// call makes (f args...) with n arguments made by arg
+func (g *generator) call(f string, n int, arg func(int) string, depth int) string {
+	args := make([]string, n)
+	for i := range args {
+		if g.r.Intn(20) == 0 {
+			args[i] = g.any(depth - 1)
+		} else {
+			args[i] = arg(depth - 1)
+		}
+	}
+	return "(" + strings.Join(append([]string{f}, args...), " ") + ")"
+}
+
+func (g *generator) any(depth int) string {
+	switch g.r.Intn(7) {
+	case 0, 1:
+		return g.integer(depth)
+	case 2:
+		return g.boolean(depth)
+	case 3:
+		return g.str(depth)
+	case 4, 5:
+		return g.seq(depth)
+	default:
+		return g.special(depth)
+	}
+}
+
+func (g *generator) atom() string {
+	switch g.r.Intn(6) {
+	case 0:
+		return fmt.Sprint(g.r.Intn(21) - 10)
+	case 1:
+		return g.pick("nil", "true", "false")
+	case 2:
+		return g.pick(`""`, `"abc"`, `"a\"b"`, `"x\\y"`, `"1\n2"`)
+	case 3:
+		return g.pick(":a", ":kw", "'sym")
+	case 4:
+		return g.pick("[]", "()", "[1 2]", "'(3 4)")
+	}
+	return fmt.Sprint(g.r.Intn(1000))
+}
+
+func (g *generator) integer(depth int) string {
+	if depth <= 0 || g.r.Intn(4) == 0 {
+		return fmt.Sprint(g.r.Intn(21) - 10)
+	}
+	switch g.r.Intn(7) {
+	case 0:
+		return g.call(g.pick("+", "*"), g.r.Intn(3)+1, g.integer, depth)
+	case 1:
+		return g.call("-", g.r.Intn(2)+1, g.integer, depth)
+	case 2:
+		return g.call("/", 2, g.integer, depth)
+	case 3:
+		return g.call("count", 1, g.seq, depth)
+	case 4:
+		return "(nth " + g.seq(depth-1) + " " + fmt.Sprint(g.r.Intn(4)) + ")"
+	case 5:
+		return "(let* [x " + g.integer(depth-1) + "] (+ x " + g.integer(depth-1) + "))"
+	}
+	return "((fn* [a b] (- a b)) " + g.integer(depth-1) + " " + g.integer(depth-1) + ")"
+}
+
+func (g *generator) boolean(depth int) string {
+	if depth <= 0 {
+		return g.pick("true", "false", "nil")
+	}
+	switch g.r.Intn(5) {
+	case 0:
+		return g.call(g.pick("<", "<=", ">", ">="), 2, g.integer, depth)
+	case 1:
+		return g.call("=", 2, g.any, depth)
+	case 2:
+		return g.call(g.pick("empty?", "list?", "vector?", "sequential?"), 1, g.seq, depth)
+	case 3:
+		return g.call(g.pick("nil?", "true?", "false?", "string?", "number?", "keyword?", "symbol?"), 1, g.any, depth)
+	}
+	return g.call("not", 1, g.any, depth)
+}
+
+func (g *generator) str(depth int) string {
+	if depth <= 0 {
+		return g.pick(`""`, `"abc"`, `"a\"b"`, `"x\\y"`, `"1\n2"`)
+	}
+	switch g.r.Intn(3) {
+	case 0:
+		return g.call("str", g.r.Intn(3), g.any, depth)
+	case 1:
+		return g.call("pr-str", g.r.Intn(3), g.any, depth)
+	}
+	return g.call("do (prn "+g.any(depth-1)+")", 1, g.str, depth)
+}
+
+func (g *generator) seq(depth int) string {
+	if depth <= 0 {
+		return g.pick("[]", "()", "[1 2]", "'(3 4)", "(list nil)")
+	}
+	switch g.r.Intn(9) {
+	case 0:
+		return g.call("list", g.r.Intn(4), g.any, depth)
+	case 1:
+		return g.call("vector", g.r.Intn(4), g.any, depth)
+	case 2:
+		return "(cons " + g.any(depth-1) + " " + g.seq(depth-1) + ")"
+	case 3:
+		return g.call("concat", g.r.Intn(3), g.seq, depth)
+	case 4:
+		return g.call("rest", 1, g.seq, depth)
+	case 5:
+		return "(conj " + g.seq(depth-1) + " " + g.any(depth-1) + ")"
+	case 6:
+		return "(map (fn* [x] (list x x)) " + g.seq(depth-1) + ")"
+	case 7:
+		return "(apply list " + g.any(depth-1) + " " + g.seq(depth-1) + ")"
+	}
+	return "`(" + g.atom() + " ~" + g.any(depth-1) + " ~@" + g.seq(depth-1) + ")"
+}
+
+func (g *generator) special(depth int) string {
+	if depth <= 0 {
+		return g.atom()
+	}
+	switch g.r.Intn(6) {
+	case 0:
+		return "(if " + g.boolean(depth-1) + " " + g.any(depth-1) + " " + g.any(depth-1) + ")"
+	case 1:
+		return "(do " + g.any(depth-1) + " " + g.any(depth-1) + ")"
+	case 2:
+		return "(first " + g.seq(depth-1) + ")"
+	case 3:
+		return "(get (hash-map " + g.atom() + " " + g.any(depth-1) + ") " + g.atom() + ")"
+	case 4:
+		return "(try* (throw " + g.any(depth-1) + ") (catch* e e))"
+	}
+	return "(deref (atom " + g.any(depth-1) + "))"
+}
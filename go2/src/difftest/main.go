@@ -0,0 +1,167 @@
+// difftest feeds the same forms to the stepA_mal of go/ and go2/,
+// and reports every form whose printed result, output or error status differs.
+//
+//	difftest -go ../go/stepA_mal -go2 ./stepA_mal [-random 200] [-seed 1]
+//
+// The forms are taken from tests/*.mal, examples/*.mal and randomly generated expressions.
+// Each file is run in a fresh process of each interpreter, so definitions in a file are kept
+// for the following forms of the file.
+package main
+
+import (
+	"flag"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	"mal"
+)
+
+func main() {
+	goBin := flag.String("go", "../go/stepA_mal", "stepA_mal of go/")
+	go2Bin := flag.String("go2", "./stepA_mal", "stepA_mal of go2/")
+	root := flag.String("root", "..", "the top directory of the mal repository")
+	random := flag.Int("random", 200, "the number of random expressions")
+	seed := flag.Int64("seed", 1, "the seed of random expressions")
+	timeout := flag.Duration("timeout", time.Minute, "timeout of an interpreter for a file")
+	verbose := flag.Bool("v", false, "print every corpus")
+	flag.Parse()
+
+	impls := []*impl{{name: "go", bin: *goBin}, {name: "go2", bin: *go2Bin}}
+	for _, im := range impls {
+		abs, err := filepath.Abs(im.bin)
+		if err != nil {
+			fmt.Println("Error: " + err.Error())
+			os.Exit(2)
+		}
+		im.bin, im.timeout = abs, *timeout
+	}
+
+	corpora, err := loadCorpora(*root)
+	if err != nil {
+		fmt.Println("Error: " + err.Error())
+		os.Exit(2)
+	}
+	if *random > 0 {
+		corpora = append(corpora, randomCorpus(*seed, *random))
+	}
+
+	total, diffs := 0, 0
+	for _, c := range corpora {
+		results := make([][]result, len(impls))
+		for i, im := range impls {
+			results[i] = im.run(c.forms)
+		}
+		n := 0
+		for i, f := range c.forms {
+			if d := diff(results[0][i], results[1][i]); d != "" {
+				fmt.Printf("%s:%d: %s\n%s", c.name, f.line, f.src, d)
+				n++
+			}
+		}
+		if *verbose || n > 0 {
+			fmt.Printf("%s: %d forms, %d differences\n\n", c.name, len(c.forms), n)
+		}
+		total += len(c.forms)
+		diffs += n
+	}
+	fmt.Printf("%d forms, %d differences\n", total, diffs)
+	if diffs > 0 {
+		os.Exit(1)
+	}
+}
+
+// corpus : forms from a file, which are evaluated in one interpreter
+type corpus struct {
+	name  string
+	forms []form
+}
+
+// form : a top level form and its line in the corpus
+type form struct {
+	line int
+	src  string
+}
+
+// loadCorpora reads tests/*.mal and examples/*.mal under root.
+// Files named step*.mal are test files for runtest.py, and the other files are mal programs.
+func loadCorpora(root string) ([]corpus, error) {
+	var files []string
+	for _, pattern := range []string{"tests/*.mal", "examples/*.mal"} {
+		fs, err := filepath.Glob(filepath.Join(root, pattern))
+		if err != nil {
+			return nil, err
+		}
+		sort.Strings(fs)
+		files = append(files, fs...)
+	}
+
+	var corpora []corpus
+	for _, file := range files {
+		src, err := readFile(file)
+		if err != nil {
+			return nil, err
+		}
+		var forms []form
+		if strings.HasPrefix(filepath.Base(file), "step") {
+			forms = testForms(src)
+		} else if forms, err = splitForms(src); err != nil {
+			return nil, fmt.Errorf("%s: %v", file, err)
+		}
+		name, err := filepath.Rel(root, file)
+		if err != nil {
+			name = file
+		}
+		corpora = append(corpora, corpus{name: name, forms: forms})
+	}
+	return corpora, nil
+}
+
+// diff describes the difference of the results of go/ and go2/, or returns "" if they agree
+func diff(g, g2 result) string {
+	same := g.status == g2.status && sameOutput(g.output, g2.output)
+	if same && g.status == ok {
+		same = samePrinted(g.value, g2.value)
+	}
+	if same {
+		return ""
+	}
+	return "    go:  " + g.String() + "\n    go2: " + g2.String() + "\n"
+}
+
+// printer reads printed values again to compare them
+var printer = mal.NewInterpreter(strings.NewReader(""), ioutil.Discard)
+
+// samePrinted compares printed values. The order of the entries of maps doesn't matter.
+func samePrinted(s, t string) bool {
+	if s == t {
+		return true
+	}
+	v, err := printer.Eval("(quote " + s + ")")
+	if err != nil {
+		return false
+	}
+	w, err := printer.Eval("(quote " + t + ")")
+	if err != nil {
+		return false
+	}
+	return v.PrintStr(true) == w.PrintStr(true)
+}
+
+// sameOutput compares the outputs line by line with samePrinted
+func sameOutput(s, t string) bool {
+	ls, lt := strings.Split(s, "\n"), strings.Split(t, "\n")
+	if len(ls) != len(lt) {
+		return false
+	}
+	for i := range ls {
+		if !samePrinted(ls[i], lt[i]) {
+			return false
+		}
+	}
+	return true
+}
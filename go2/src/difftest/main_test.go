@@ -0,0 +1,68 @@
+package main
+
+import "testing"
+
+func TestSplitForms(test *testing.T) {
+	forms, err := splitForms(`;; comment
+(def! a "x)\"y") ; comment
+  [1 2
+   {:a 3}]
+'(a b) ~@c ^{"m" 1} [] sym`)
+	if err != nil {
+		test.Fatal(err)
+	}
+	expected := []form{
+		{2, `(def! a "x)\"y")`},
+		{3, "[1 2\n   {:a 3}]"},
+		{5, "'(a b)"},
+		{5, "~@c"},
+		{5, `^{"m" 1} []`},
+		{5, "sym"},
+	}
+	if len(forms) != len(expected) {
+		test.Fatalf("Expected: %v\nbut actually got: %v\n", expected, forms)
+	}
+	for i, f := range forms {
+		if f != expected[i] {
+			test.Errorf("Expected: %v\nbut actually got: %v\n", expected[i], f)
+		}
+	}
+
+	if _, err := splitForms("(a (b)"); err == nil {
+		test.Error("unbalanced form should be an error")
+	}
+}
+
+func TestParseOutput(test *testing.T) {
+	out := formMarker + "\n" + valueMarker + "\n3\n" +
+		formMarker + "\nhello\n" + errorMarker + "\n\"oops\"\n" +
+		formMarker + "\nprompt> " + valueMarker + "\nnil\n" +
+		formMarker + "\npartial\n"
+	results := make([]result, 5)
+	if n := parseOutput(out, results); n != 3 {
+		test.Errorf("Expected 3 forms but actually got %d", n)
+	}
+	expected := []result{
+		{ok, "", "3"},
+		{failed, "hello\n", `"oops"`},
+		{ok, "prompt> ", "nil"},
+		{crashed, "partial\n", ""},
+	}
+	for i, r := range expected {
+		if results[i] != r {
+			test.Errorf("Expected: %v\nbut actually got: %v\n", r, results[i])
+		}
+	}
+}
+
+func TestSamePrinted(test *testing.T) {
+	if !samePrinted(`{"a" 1 "b" (2)}`, `{"b" (2) "a" 1}`) {
+		test.Error("the order of the entries of maps should not matter")
+	}
+	if samePrinted("(1 2)", "[1 2]") || samePrinted(`"a"`, "a") {
+		test.Error("different values are the same")
+	}
+	if malString("a\"b\\c\nd") != `"a\"b\\c\nd"` {
+		test.Error("malString: " + malString("a\"b\\c\nd"))
+	}
+}
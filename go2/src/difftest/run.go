@@ -0,0 +1,176 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"time"
+)
+
+const (
+	formMarker  = "<<<difftest form>>>"
+	valueMarker = "<<<difftest value>>>"
+	errorMarker = "<<<difftest error>>>"
+)
+
+// prologue defines the function evaluating a form given as a string.
+// It prints the markers by which the output of the script is split into the results of the forms.
+// The core functions are captured so that the forms can redefine them.
+var prologue = `(def! __difftest-eval
+  (let* [println println prn prn eval eval read-string read-string]
+    (fn* [s]
+      (do
+        (println "` + formMarker + `")
+        (try*
+          (let* [v (eval (read-string s))]
+            (do (println "` + valueMarker + `") (prn v)))
+          (catch* e
+            (do (println "` + errorMarker + `") (prn e))))))))
+`
+
+type status int
+
+const (
+	ok status = iota
+	failed
+	crashed
+)
+
+// result : what an interpreter did for a form
+type result struct {
+	status status
+	output string // printed by the form
+	value  string // the printed value, the exception, or why the interpreter crashed
+}
+
+func (r result) String() string {
+	s := r.value
+	switch r.status {
+	case failed:
+		s = "error " + s
+	case crashed:
+		s = "crashed: " + s
+	}
+	if r.output != "" {
+		s += fmt.Sprintf(" (output %q)", r.output)
+	}
+	return s
+}
+
+// impl : stepA_mal of an implementation
+type impl struct {
+	name    string
+	bin     string
+	timeout time.Duration
+}
+
+// run evaluates forms in order. When the interpreter crashes at a form,
+// it is restarted from the next form, without the definitions made so far.
+func (im *impl) run(forms []form) []result {
+	results := make([]result, len(forms))
+	for start := 0; start < len(forms); {
+		n, reason := im.runScript(forms[start:], results[start:])
+		if start+n == len(forms) {
+			break
+		}
+		results[start+n].status = crashed
+		results[start+n].value = reason
+		start += n + 1
+	}
+	return results
+}
+
+// runScript runs forms as a script and fills results.
+// It returns the number of the forms evaluated, and why the interpreter stopped if not all of them.
+func (im *impl) runScript(forms []form, results []result) (int, string) {
+	script, err := ioutil.TempFile("", "difftest-*.mal")
+	if err != nil {
+		return 0, err.Error()
+	}
+	defer os.Remove(script.Name())
+	script.WriteString(prologue)
+	for _, f := range forms {
+		script.WriteString("(__difftest-eval " + malString(f.src) + ")\n")
+	}
+	script.Close()
+
+	ctx, cancel := context.WithTimeout(context.Background(), im.timeout)
+	defer cancel()
+	cmd := exec.CommandContext(ctx, im.bin, script.Name())
+	cmd.Dir = filepath.Dir(im.bin) // like runtest.py, run in the implementation directory
+	var stdout, stderr bytes.Buffer
+	cmd.Stdout, cmd.Stderr = &stdout, &stderr
+	err = cmd.Run()
+
+	n := parseOutput(stdout.String(), results)
+	if n == len(forms) {
+		return n, ""
+	}
+	switch {
+	case ctx.Err() != nil:
+		return n, "timeout"
+	case stderr.Len() > 0:
+		return n, strings.SplitN(stderr.String(), "\n", 2)[0]
+	case err != nil:
+		return n, err.Error()
+	}
+	return n, "exited"
+}
+
+// parseOutput splits the output of a script into results.
+// It returns the number of the forms whose value or exception is printed.
+func parseOutput(out string, results []result) int {
+	i := -1
+	inValue := false
+lines:
+	for _, line := range strings.SplitAfter(out, "\n") {
+		// a marker may follow the output without a newline, e.g. a prompt of readline
+		marker := ""
+		for _, m := range []string{formMarker, valueMarker, errorMarker} {
+			if strings.HasSuffix(line, m+"\n") {
+				marker = m
+				line = strings.TrimSuffix(line, m+"\n")
+			}
+		}
+		if i >= 0 {
+			if inValue {
+				results[i].value += line
+			} else {
+				results[i].output += line
+			}
+		}
+		switch marker {
+		case formMarker:
+			if i+1 == len(results) {
+				break lines
+			}
+			i++
+			results[i] = result{status: crashed}
+			inValue = false
+		case valueMarker:
+			results[i].status = ok
+			inValue = true
+		case errorMarker:
+			results[i].status = failed
+			inValue = true
+		}
+	}
+	for j := 0; j <= i; j++ {
+		results[j].value = strings.TrimSuffix(results[j].value, "\n")
+	}
+	if i >= 0 && results[i].status == crashed {
+		return i
+	}
+	return i + 1
+}
+
+// malString quotes s as a mal string literal
+func malString(s string) string {
+	r := strings.NewReplacer(`\`, `\\`, `"`, `\"`, "\n", `\n`)
+	return `"` + r.Replace(s) + `"`
+}
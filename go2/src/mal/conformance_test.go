@@ -0,0 +1,198 @@
+package mal
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+	"testing"
+)
+
+// testCase : a form in tests/*.mal with the expected output and value.
+// It is read in the same way as runtest.py does.
+type testCase struct {
+	line int
+	form string
+	out  []string // patterns of the output lines. '; ' lines are quoted, ';/' lines are regexps
+	ret  string   // "*" means the result isn't checked
+	soft bool
+}
+
+// readTestCases parses a test file for runtest.py
+func readTestCases(src string) ([]testCase, error) {
+	var cases []testCase
+	lines := strings.Split(src, "\n")
+	soft := false
+	for i := 0; i < len(lines); i++ {
+		line := lines[i]
+		switch {
+		case strings.TrimSpace(line) == "":
+			continue
+		case strings.HasPrefix(line, ";;"): // comment or message
+			continue
+		case strings.HasPrefix(line, ";>>> "): // settings
+			if strings.Contains(line, "soft=True") {
+				soft = true
+			} else if strings.Contains(line, "soft=False") {
+				soft = false
+			}
+			continue
+		case strings.HasPrefix(line, ";"):
+			return nil, fmt.Errorf("test data error at line %d: %s", i+1, line)
+		}
+
+		c := testCase{line: i + 1, form: line, ret: "*", soft: soft}
+		for i+1 < len(lines) {
+			next := lines[i+1]
+			if strings.HasPrefix(next, ";=>") {
+				c.ret = next[3:]
+				i++
+				break
+			} else if strings.HasPrefix(next, "; ") {
+				c.out = append(c.out, regexp.QuoteMeta(next[2:]))
+				i++
+			} else if strings.HasPrefix(next, ";/") {
+				c.out = append(c.out, next[2:])
+				i++
+			} else {
+				break
+			}
+		}
+		cases = append(cases, c)
+	}
+	return cases, nil
+}
+
+// check returns whether got, the output followed by the printed value, is expected by c
+func (c testCase) check(got string) bool {
+	if c.ret == "*" {
+		return true
+	}
+	pattern := ""
+	for _, o := range c.out {
+		pattern += o + "\n"
+	}
+	pattern += regexp.QuoteMeta(c.ret)
+	re, err := regexp.Compile("^(?:" + pattern + ")$")
+	return err == nil && re.MatchString(got)
+}
+
+// feeder : stdin of the interpreter under test.
+// Like the terminal of runtest.py, it gives the following forms to readline.
+// The output until readline reads a case is that of the case before it,
+// so it is saved in outs for checking that case.
+type feeder struct {
+	cases []testCase
+	next  int
+	buf   []byte
+	out   *bytes.Buffer
+	outs  []string
+}
+
+func (f *feeder) Read(p []byte) (int, error) {
+	if len(f.buf) == 0 {
+		if f.next >= len(f.cases) {
+			return 0, io.EOF
+		}
+		// runtest.py takes the output until the prompt of readline as that of the last form
+		f.outs = append(f.outs, f.out.String())
+		f.out.Reset()
+		f.buf = []byte(f.cases[f.next].form + "\n")
+		f.next++
+	}
+	n := copy(p, f.buf)
+	f.buf = f.buf[n:]
+	return n, nil
+}
+
+// runTestFile runs the forms in the test file like the REPL of stepA_mal.
+// If readOnly, the forms are only read and printed like step1_read_print.
+func runTestFile(test *testing.T, file string, readOnly bool) (pass, fail, softFail int) {
+	src, err := ioutil.ReadFile(file)
+	if err != nil {
+		test.Fatal(err)
+	}
+	cases, err := readTestCases(string(src))
+	if err != nil {
+		test.Fatal(err)
+	}
+
+	out := &bytes.Buffer{}
+	stdin := &feeder{cases: cases, out: out}
+	in := NewInterpreter(stdin, out)
+	for stdin.next < len(cases) {
+		first := stdin.next
+		stdin.next++
+		out.Reset()
+		stdin.outs = nil
+		var v SExp
+		if readOnly {
+			v, err = initReader(cases[first].form).readForm()
+		} else {
+			v, err = in.Eval(cases[first].form)
+		}
+		got := out.String()
+		if err != nil {
+			got += "Error: " + err.Error()
+		} else if v != UNDEF {
+			got += v.PrintStr(true)
+		}
+
+		// the cases read by readline are checked too, each with the output
+		// before the next one was read, and the last one with got
+		for k, c := range cases[first:stdin.next] {
+			o := got
+			if k < len(stdin.outs) {
+				o = stdin.outs[k]
+			}
+			if c.check(o) {
+				pass++
+			} else if c.soft {
+				softFail++
+				test.Logf("SOFT FAILED TEST (%s:%d): %s\nExpected: %v\nbut actually got: %v\n", file, c.line, c.form, c.ret, o)
+			} else {
+				fail++
+				test.Errorf("FAILED TEST (%s:%d): %s\nExpected: %v\nbut actually got: %v\n", file, c.line, c.form, c.ret, o)
+			}
+		}
+	}
+	return
+}
+
+// TestConformance runs the shared tests/step*.mal and the go2 specific go2/tests/step*.mal.
+// Run 'go test -v' to see the counts of each step.
+func TestConformance(test *testing.T) {
+	// the tests load files relative to the implementation directory
+	wd, err := os.Getwd()
+	if err != nil {
+		test.Fatal(err)
+	}
+	if err := os.Chdir("../.."); err != nil {
+		test.Fatal(err)
+	}
+	defer os.Chdir(wd)
+
+	files, err := filepath.Glob("../tests/step*.mal")
+	if err != nil {
+		test.Fatal(err)
+	}
+	for _, file := range files {
+		step := strings.TrimSuffix(filepath.Base(file), ".mal")
+		if step == "step0_repl" { // nothing but echo
+			continue
+		}
+		test.Run(step, func(test *testing.T) {
+			readOnly := step == "step1_read_print"
+			pass, fail, softFail := runTestFile(test, file, readOnly)
+			if _, err := os.Stat(filepath.Join("tests", step+".mal")); err == nil {
+				p, f, s := runTestFile(test, filepath.Join("tests", step+".mal"), readOnly)
+				pass, fail, softFail = pass+p, fail+f, softFail+s
+			}
+			test.Logf("%s: %d passed, %d failed, %d soft failed", step, pass, fail, softFail)
+		})
+	}
+}
@@ -0,0 +1,808 @@
+package mal
+
+import (
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"strings"
+	"time"
+)
+
+type envInternal map[Symbol]SExp
+
+func makeEnvInternal() envInternal { return make(envInternal) }
+
+// Env : Environment. Env is shared by reference, so a closure sees definitions made after it was created
+type Env struct {
+	env     envInternal
+	nextEnv *Env
+}
+
+func (e Env) Set(sym Symbol, sexp SExp) {
+	e.env[sym] = sexp
+}
+
+func (e Env) Get(sym Symbol) (SExp, bool) {
+	v, ok := e.env[sym]
+	if ok || e.nextEnv == nil {
+		return v, ok
+	}
+	return e.nextEnv.Get(sym)
+}
+
+func (e Env) Del(sym Symbol) {
+	delete(e.env, sym)
+}
+
+func makeNewEnv(e Env) Env {
+	return Env{
+		env:     make(envInternal),
+		nextEnv: &e,
+	}
+}
+
+// initREPLEnv defines the core functions in a new top level environment of in
+func (in *Interpreter) initREPLEnv() {
+	in.env = Env{
+		env:     makeEnvInternal(),
+		nextEnv: nil,
+	}
+	// ints converts the arguments of the function name to ints
+	ints := func(name string, args List) ([]int, error) {
+		ret := make([]int, len(args))
+		for i, v := range args {
+			n, ok := v.(Int)
+			if !ok {
+				return nil, errors.New(name + "'s arguments should be Int ... but got " + v.PrintStr(true))
+			}
+			ret[i] = int(n)
+		}
+		return ret, nil
+	}
+	plus := CoreFunc(
+		func(args List, _ Env) (SExp, error) {
+			xs, err := ints("+", args)
+			if err != nil {
+				return UNDEF, err
+			}
+			s := 0
+			for _, x := range xs {
+				s += x
+			}
+			return Int(s), nil
+		})
+	minus := CoreFunc(
+		func(args List, _ Env) (SExp, error) {
+			if len(args) == 0 {
+				return UNDEF, errors.New("'(- INT INT ...)'")
+			}
+			xs, err := ints("-", args)
+			if err != nil {
+				return UNDEF, err
+			}
+			s := xs[0]
+			for _, x := range xs[1:] {
+				s -= x
+			}
+			return Int(s), nil
+		})
+	times := CoreFunc(
+		func(args List, _ Env) (SExp, error) {
+			xs, err := ints("*", args)
+			if err != nil {
+				return UNDEF, err
+			}
+			s := 1
+			for _, x := range xs {
+				s *= x
+			}
+			return Int(s), nil
+		})
+	div := CoreFunc(
+		func(args List, _ Env) (SExp, error) {
+			if len(args) == 0 {
+				return UNDEF, errors.New("'(/ INT INT ...)'")
+			}
+			xs, err := ints("/", args)
+			if err != nil {
+				return UNDEF, err
+			}
+			s := xs[0]
+			for _, x := range xs[1:] {
+				if x == 0 {
+					return UNDEF, errors.New("division by zero")
+				}
+				s /= x
+			}
+			return Int(s), nil
+		})
+	cmp := func(name string, f func(x, y int) bool) CoreFunc {
+		return CoreFunc(func(args List, _ Env) (SExp, error) {
+			if len(args) != 2 {
+				return UNDEF, errors.New("'(" + name + " INT INT)'")
+			}
+			xs, err := ints(name, args)
+			if err != nil {
+				return UNDEF, err
+			}
+			return Bool(f(xs[0], xs[1])), nil
+		})
+	}
+	lt := cmp("<", func(x, y int) bool { return x < y })
+	le := cmp("<=", func(x, y int) bool { return x <= y })
+	gt := cmp(">", func(x, y int) bool { return x > y })
+	ge := cmp(">=", func(x, y int) bool { return x >= y })
+	eq := CoreFunc(func(args List, _ Env) (SExp, error) {
+		if len(args) != 2 {
+			return UNDEF, errors.New("'(= EXP EXP)'")
+		}
+		return Bool(args[0].Equal(args[1])), nil
+	})
+	list := CoreFunc(
+		func(args List, _ Env) (SExp, error) {
+			return args, nil
+		})
+	emptyq := CoreFunc(
+		func(args List, _ Env) (SExp, error) {
+			if len(args) != 1 {
+				return UNDEF, errors.New("'(empty? LIST)'")
+			}
+			switch args[0].(type) {
+			case List:
+				return Bool(len(args[0].(List)) == 0), nil
+			case Vector:
+				return Bool(len(args[0].(Vector)) == 0), nil
+			case HashMap:
+				return Bool(len(args[0].(HashMap)) == 0), nil
+			case NilType:
+				return Bool(true), nil
+			default:
+				return UNDEF, errors.New("empty?'s argument should be List, Vector or HashMap")
+			}
+		})
+	count := CoreFunc(
+		func(args List, _ Env) (SExp, error) {
+			if len(args) != 1 {
+				return UNDEF, errors.New("'(count LIST)'")
+			}
+			switch args[0].(type) {
+			case List:
+				return Int(len(args[0].(List))), nil
+			case Vector:
+				return Int(len(args[0].(Vector))), nil
+			case HashMap:
+				return Int(len(args[0].(HashMap))), nil
+			case NilType:
+				return Int(0), nil
+			default:
+				return UNDEF, errors.New("count's argument should be List, Vector or HashMap")
+			}
+		})
+	not := CoreFunc(func(args List, _ Env) (SExp, error) {
+		if len(args) != 1 {
+			return UNDEF, errors.New("'(not EXP)'")
+		}
+		b := true
+		switch args[0].(type) {
+		case NilType:
+			b = false
+		case Bool:
+			b = bool(args[0].(Bool))
+		case List:
+			list := args[0].(List)
+			b = len(list) == 0
+		}
+		return Bool(!b), nil
+	})
+	prstr := CoreFunc(
+		func(args List, _ Env) (SExp, error) {
+			s := printStrList(args, true, " ")
+			return StringLiteral(s), nil
+		})
+	prn := CoreFunc(
+		func(args List, _ Env) (SExp, error) {
+			fmt.Fprintln(in.stdout, printStrList(args, true, " "))
+			return NIL, nil
+		})
+	str := CoreFunc(
+		func(args List, _ Env) (SExp, error) {
+			s := ""
+			for _, a := range args {
+				s += a.PrintStr(false)
+			}
+			return StringLiteral(s), nil
+		})
+	printlnCF := CoreFunc(
+		func(args List, _ Env) (SExp, error) {
+			fmt.Fprintln(in.stdout, printStrList(args, false, " "))
+			return NIL, nil
+		})
+	readString := CoreFunc(
+		func(args List, _ Env) (SExp, error) {
+			if len(args) != 1 {
+				return UNDEF, errors.New("'(read-string STRING)'")
+			}
+			s, ok := args[0].(StringLiteral)
+			if !ok {
+				return UNDEF, errors.New("read-string's argument should be String")
+			}
+			r := initReader(string(s))
+			sexp, err := r.readForm()
+			if err == nil && sexp == UNDEF { // nothing to read
+				return NIL, nil
+			}
+			return sexp, err
+		})
+	evalCore := CoreFunc(
+		func(args List, env Env) (SExp, error) {
+			if len(args) != 1 {
+				return UNDEF, errors.New("'(eval EXP)'")
+			}
+			return args[0].Eval(in.env)
+		})
+	slurp := CoreFunc(
+		func(args List, env Env) (SExp, error) {
+			if len(args) != 1 {
+				return UNDEF, errors.New("'(slurp FILENAME)'")
+			}
+			fn, ok := args[0].(StringLiteral)
+			if !ok {
+				return UNDEF, errors.New("slurp's argument should be String")
+			}
+			fp, err := os.Open(string(fn))
+			if err != nil {
+				return NIL, err
+			}
+			defer fp.Close()
+			contents, err := ioutil.ReadAll(fp)
+			if err != nil {
+				return NIL, err
+			}
+			return StringLiteral(contents), nil
+		})
+	loadFile := CoreFunc(
+		func(args List, env Env) (SExp, error) {
+			if len(args) != 1 {
+				return UNDEF, errors.New("'(load-file FILENAME)'")
+			}
+			s, err := slurp.apply(args, env)
+			if err != nil {
+				return NIL, err
+			}
+			str := s.(StringLiteral) // slurp returns String unless err
+			r := initReader(string(str))
+			var val SExp = NIL
+			for !r.isReachedEND {
+				sexp, err := r.readForm()
+				if err != nil {
+					return NIL, err
+				}
+				buf, err := sexp.Eval(in.env)
+				if err != nil {
+					return val, err
+				}
+				if buf != UNDEF {
+					val = buf
+				}
+			}
+			return val, nil
+		})
+	atom := CoreFunc(
+		func(args List, env Env) (SExp, error) {
+			if len(args) != 1 {
+				return UNDEF, errors.New("'(atom EXP)'")
+			}
+			return &Atom{
+				ref: args[0],
+			}, nil
+		})
+	deref := CoreFunc(
+		func(args List, env Env) (SExp, error) {
+			if len(args) != 1 {
+				return UNDEF, errors.New("'(deref ATOM)'")
+			}
+			switch a := args[0].(type) {
+			case *Atom:
+				return a.ref, nil
+			}
+			return UNDEF, errors.New("deref's argument should be Atom")
+		})
+	cons := CoreFunc(
+		func(args List, env Env) (SExp, error) {
+			if len(args) != 2 {
+				return UNDEF, errors.New("'(cons EXP LIST)'")
+			}
+			var tail []SExp
+			switch l := withoutMeta(args[1]).(type) {
+			case List:
+				tail = l
+			case Vector:
+				tail = l
+			case NilType:
+			default:
+				return UNDEF, errors.New("cons's second argument should be List or Vector")
+			}
+			ret := make(List, 0, len(tail)+1)
+			ret = append(ret, args[0])
+			return append(ret, tail...), nil
+		})
+	concat := CoreFunc(
+		func(args List, env Env) (SExp, error) {
+			ret := make(List, 0)
+			for _, a := range args {
+				switch l := a.(type) {
+				case List:
+					ret = append(ret, l...)
+				case Vector:
+					ret = append(ret, l...)
+				case NilType:
+				default:
+					return UNDEF, errors.New("concat's arguments should be List or Vector")
+				}
+			}
+			return ret, nil
+		})
+	nth := CoreFunc(
+		func(args List, env Env) (SExp, error) {
+			if len(args) != 2 {
+				return UNDEF, errors.New("'(nth LIST INDEX)'")
+			}
+			i, ok := args[1].(Int)
+			if !ok {
+				return UNDEF, errors.New("nth's index should be Int")
+			}
+			if !isSequential(args[0]) {
+				return UNDEF, errors.New("nth's first argument should be List or Vector")
+			}
+			l := toList(args[0])
+			if int(i) < 0 || int(i) >= len(l) {
+				return UNDEF, errors.New("nth: index out of range")
+			}
+			return l[i], nil
+		})
+	first := CoreFunc(
+		func(args List, env Env) (SExp, error) {
+			if len(args) != 1 {
+				return UNDEF, errors.New("'(first LIST)'")
+			}
+			if !isSequential(args[0]) && !args[0].Equal(NIL) {
+				return UNDEF, errors.New("first's argument should be List, Vector or nil")
+			}
+			l := toList(args[0])
+			if len(l) == 0 {
+				return NIL, nil
+			}
+			return l[0], nil
+		})
+	rest := CoreFunc(
+		func(args List, env Env) (SExp, error) {
+			if len(args) != 1 {
+				return UNDEF, errors.New("'(rest LIST)'")
+			}
+			if !isSequential(args[0]) && !args[0].Equal(NIL) {
+				return UNDEF, errors.New("rest's argument should be List, Vector or nil")
+			}
+			l := toList(args[0])
+			if len(l) == 0 {
+				return List{}, nil
+			}
+			return append(List{}, l[1:]...), nil
+		})
+	throw := CoreFunc(
+		func(args List, env Env) (SExp, error) {
+			if len(args) != 1 {
+				return UNDEF, errors.New("'(throw EXP)'")
+			}
+			return UNDEF, Exception{value: args[0]}
+		})
+	typeq := func(f func(s SExp) bool) CoreFunc {
+		return CoreFunc(func(args List, _ Env) (SExp, error) {
+			if len(args) != 1 {
+				return UNDEF, errors.New("type predicates take 1 argument")
+			}
+			return Bool(f(args[0])), nil
+		})
+	}
+	nilq := typeq(func(s SExp) bool { return s.Equal(NIL) })
+	trueq := typeq(func(s SExp) bool { return s.Equal(Bool(true)) })
+	falseq := typeq(func(s SExp) bool { return s.Equal(Bool(false)) })
+	symbolq := typeq(func(s SExp) bool { _, ok := s.(Symbol); return ok })
+	keywordq := typeq(func(s SExp) bool { _, ok := s.(Keyword); return ok })
+	vectorq := typeq(func(s SExp) bool { _, ok := s.(Vector); return ok })
+	mapq := typeq(func(s SExp) bool { _, ok := s.(HashMap); return ok })
+	listq := typeq(func(s SExp) bool { _, ok := s.(List); return ok })
+	atomq := typeq(func(s SExp) bool { _, ok := s.(*Atom); return ok })
+	sequentialq := typeq(isSequential)
+	symbol := CoreFunc(
+		func(args List, env Env) (SExp, error) {
+			if len(args) != 1 {
+				return UNDEF, errors.New("'(symbol STRING)'")
+			}
+			s, ok := args[0].(StringLiteral)
+			if !ok {
+				return UNDEF, errors.New("symbol's argument should be String")
+			}
+			return Symbol(s), nil
+		})
+	keyword := CoreFunc(
+		func(args List, env Env) (SExp, error) {
+			if len(args) != 1 {
+				return UNDEF, errors.New("'(keyword STRING)'")
+			}
+			switch s := args[0].(type) {
+			case StringLiteral:
+				return Keyword(s), nil
+			case Keyword:
+				return s, nil
+			}
+			return UNDEF, errors.New("keyword's argument should be String")
+		})
+	vector := CoreFunc(
+		func(args List, env Env) (SExp, error) {
+			return Vector(append(List{}, args...)), nil
+		})
+	apply := CoreFunc(
+		func(args List, env Env) (SExp, error) {
+			if len(args) < 2 {
+				return UNDEF, errors.New("'(apply FUNC ARGS... LIST)'")
+			}
+			last := withoutMeta(args[len(args)-1])
+			if !isSequential(last) {
+				return UNDEF, errors.New("apply's last argument should be List or Vector")
+			}
+			fargs := append(List{}, args[1:len(args)-1]...)
+			fargs = append(fargs, toList(last)...)
+			return applyFunc(args[0], fargs, env)
+		})
+	mapCF := CoreFunc(
+		func(args List, env Env) (SExp, error) {
+			if len(args) != 2 {
+				return UNDEF, errors.New("'(map FUNC LIST)'")
+			}
+			if !isSequential(args[1]) {
+				return UNDEF, errors.New("map's second argument should be List or Vector")
+			}
+			l := toList(args[1])
+			ret := make(List, len(l))
+			for i, v := range l {
+				var err error
+				ret[i], err = applyFunc(args[0], List{v}, env)
+				if err != nil {
+					return UNDEF, err
+				}
+			}
+			return ret, nil
+		})
+	resetCF := CoreFunc(
+		func(args List, env Env) (SExp, error) {
+			if len(args) != 2 {
+				return UNDEF, errors.New("'(reset! ATOM EXP)'")
+			}
+			a, ok := args[0].(*Atom)
+			if !ok {
+				return UNDEF, errors.New("reset!'s first argument should be Atom")
+			}
+			a.ref = args[1]
+			return a.ref, nil
+		})
+	swap := CoreFunc(
+		func(args List, env Env) (SExp, error) {
+			if len(args) < 2 {
+				return UNDEF, errors.New("'(swap! ATOM FUNC ARGS...)'")
+			}
+			a, ok := args[0].(*Atom)
+			if !ok {
+				return UNDEF, errors.New("swap!'s first argument should be Atom")
+			}
+			fargs := append(List{a.ref}, args[2:]...)
+			v, err := applyFunc(args[1], fargs, env)
+			if err != nil {
+				return UNDEF, err
+			}
+			a.ref = v
+			return v, nil
+		})
+	numberq := typeq(func(s SExp) bool { _, ok := s.(Int); return ok })
+	stringq := typeq(func(s SExp) bool { _, ok := s.(StringLiteral); return ok })
+	fnq := typeq(func(s SExp) bool {
+		switch s := s.(type) {
+		case CoreFunc:
+			return true
+		case Closure:
+			return !s.isMacro
+		}
+		return false
+	})
+	macroq := typeq(func(s SExp) bool {
+		c, ok := s.(Closure)
+		return ok && c.isMacro
+	})
+	hashMap := CoreFunc(
+		func(args List, env Env) (SExp, error) {
+			hm, err := makeHashMap(args)
+			if err != nil {
+				return UNDEF, err
+			}
+			return hm, nil
+		})
+	assoc := CoreFunc(
+		func(args List, env Env) (SExp, error) {
+			if len(args) == 0 {
+				return UNDEF, errors.New("'(assoc MAP KEY VALUE ...)'")
+			}
+			hm, ok := withoutMeta(args[0]).(HashMap)
+			if !ok {
+				return UNDEF, errors.New("assoc's first argument should be HashMap")
+			}
+			ret, err := hm.Assoc(args[1:])
+			if err != nil {
+				return UNDEF, err
+			}
+			return ret, nil
+		})
+	dissoc := CoreFunc(
+		func(args List, env Env) (SExp, error) {
+			if len(args) == 0 {
+				return UNDEF, errors.New("'(dissoc MAP KEY ...)'")
+			}
+			hm, ok := args[0].(HashMap)
+			if !ok {
+				return UNDEF, errors.New("dissoc's first argument should be HashMap")
+			}
+			return hm.Dissoc(args[1:]), nil
+		})
+	get := CoreFunc(
+		func(args List, env Env) (SExp, error) {
+			if len(args) != 2 {
+				return UNDEF, errors.New("'(get MAP KEY)'")
+			}
+			switch hm := args[0].(type) {
+			case HashMap:
+				if v, ok := hm.Get(args[1]); ok {
+					return v, nil
+				}
+				return NIL, nil
+			case NilType:
+				return NIL, nil
+			}
+			return UNDEF, errors.New("get's first argument should be HashMap")
+		})
+	containsq := CoreFunc(
+		func(args List, env Env) (SExp, error) {
+			if len(args) != 2 {
+				return UNDEF, errors.New("'(contains? MAP KEY)'")
+			}
+			switch hm := args[0].(type) {
+			case HashMap:
+				_, ok := hm.Get(args[1])
+				return Bool(ok), nil
+			case NilType:
+				return Bool(false), nil
+			}
+			return UNDEF, errors.New("contains?'s first argument should be HashMap")
+		})
+	keys := CoreFunc(
+		func(args List, env Env) (SExp, error) {
+			if len(args) != 1 {
+				return UNDEF, errors.New("'(keys MAP)'")
+			}
+			hm, ok := args[0].(HashMap)
+			if !ok {
+				return UNDEF, errors.New("keys's argument should be HashMap")
+			}
+			return List(hm.Keys()), nil
+		})
+	vals := CoreFunc(
+		func(args List, env Env) (SExp, error) {
+			if len(args) != 1 {
+				return UNDEF, errors.New("'(vals MAP)'")
+			}
+			hm, ok := args[0].(HashMap)
+			if !ok {
+				return UNDEF, errors.New("vals's argument should be HashMap")
+			}
+			ret := make(List, 0, len(hm))
+			for _, key := range hm.Keys() {
+				ret = append(ret, hm[key])
+			}
+			return ret, nil
+		})
+	conj := CoreFunc(
+		func(args List, env Env) (SExp, error) {
+			if len(args) == 0 {
+				return UNDEF, errors.New("'(conj LIST EXP ...)'")
+			}
+			switch l := withoutMeta(args[0]).(type) {
+			case List:
+				ret := make(List, 0, len(l)+len(args)-1)
+				for i := len(args) - 1; i > 0; i-- {
+					ret = append(ret, args[i])
+				}
+				return append(ret, l...), nil
+			case Vector:
+				ret := append(Vector{}, l...)
+				return append(ret, args[1:]...), nil
+			}
+			return UNDEF, errors.New("conj's first argument should be List or Vector")
+		})
+	seq := CoreFunc(
+		func(args List, env Env) (SExp, error) {
+			if len(args) != 1 {
+				return UNDEF, errors.New("'(seq EXP)'")
+			}
+			switch s := args[0].(type) {
+			case List:
+				if len(s) == 0 {
+					return NIL, nil
+				}
+				return s, nil
+			case Vector:
+				if len(s) == 0 {
+					return NIL, nil
+				}
+				return s.toList(), nil
+			case StringLiteral:
+				if len(s) == 0 {
+					return NIL, nil
+				}
+				ret := make(List, 0, len(s))
+				for _, r := range string(s) {
+					ret = append(ret, StringLiteral(r))
+				}
+				return ret, nil
+			case NilType:
+				return NIL, nil
+			}
+			return UNDEF, errors.New("seq's argument should be List, Vector or String")
+		})
+	meta := CoreFunc(
+		func(args List, env Env) (SExp, error) {
+			if len(args) != 1 {
+				return UNDEF, errors.New("'(meta EXP)'")
+			}
+			switch a := args[0].(type) {
+			case Closure:
+				if a.meta != nil {
+					return a.meta, nil
+				}
+			case WithMeta:
+				return a.meta, nil
+			}
+			return NIL, nil
+		})
+	withMeta := CoreFunc(
+		func(args List, env Env) (SExp, error) {
+			if len(args) != 2 {
+				return UNDEF, errors.New("'(with-meta EXP META)'")
+			}
+			switch a := withoutMeta(args[0]).(type) {
+			case Closure:
+				a.meta = args[1]
+				return a, nil
+			case List, Vector, HashMap, CoreFunc:
+				return WithMeta{a, args[1]}, nil
+			}
+			return UNDEF, errors.New("with-meta's first argument should be List, Vector, HashMap or function")
+		})
+	timeMs := CoreFunc(
+		func(args List, env Env) (SExp, error) {
+			return Int(time.Now().UnixNano() / int64(time.Millisecond)), nil
+		})
+	readlineCF := CoreFunc(
+		func(args List, env Env) (SExp, error) {
+			if len(args) != 1 {
+				return UNDEF, errors.New("'(readline PROMPT)'")
+			}
+			p, ok := args[0].(StringLiteral)
+			if !ok {
+				return UNDEF, errors.New("readline's argument should be String")
+			}
+			line, ok := in.ReadLine(string(p))
+			if !ok {
+				return NIL, nil
+			}
+			return StringLiteral(line), nil
+		})
+	in.env.Set(Symbol("+"), plus)
+	in.env.Set(Symbol("-"), minus)
+	in.env.Set(Symbol("*"), times)
+	in.env.Set(Symbol("/"), div)
+	in.env.Set(Symbol("<"), lt)
+	in.env.Set(Symbol("<="), le)
+	in.env.Set(Symbol(">"), gt)
+	in.env.Set(Symbol(">="), ge)
+	in.env.Set(Symbol("="), eq)
+	in.env.Set(Symbol("list"), list)
+	in.env.Set(Symbol("list?"), listq)
+	in.env.Set(Symbol("empty?"), emptyq)
+	in.env.Set(Symbol("count"), count)
+	in.env.Set(Symbol("not"), not)
+	in.env.Set(Symbol("prn"), prn)
+	in.env.Set(Symbol("str"), str)
+	in.env.Set(Symbol("pr-str"), prstr)
+	in.env.Set(Symbol("println"), printlnCF)
+	in.env.Set(Symbol("read-string"), readString)
+	in.env.Set(Symbol("eval"), evalCore)
+	in.env.Set(Symbol("slurp"), slurp)
+	in.env.Set(Symbol("load-file"), loadFile)
+	in.env.Set(Symbol("atom"), atom)
+	in.env.Set(Symbol("atom?"), atomq)
+	in.env.Set(Symbol("deref"), deref)
+	in.env.Set(Symbol("cons"), cons)
+	in.env.Set(Symbol("concat"), concat)
+	in.env.Set(Symbol("nth"), nth)
+	in.env.Set(Symbol("first"), first)
+	in.env.Set(Symbol("rest"), rest)
+	in.env.Set(Symbol("throw"), throw)
+	in.env.Set(Symbol("nil?"), nilq)
+	in.env.Set(Symbol("true?"), trueq)
+	in.env.Set(Symbol("false?"), falseq)
+	in.env.Set(Symbol("symbol?"), symbolq)
+	in.env.Set(Symbol("keyword?"), keywordq)
+	in.env.Set(Symbol("vector?"), vectorq)
+	in.env.Set(Symbol("map?"), mapq)
+	in.env.Set(Symbol("sequential?"), sequentialq)
+	in.env.Set(Symbol("symbol"), symbol)
+	in.env.Set(Symbol("keyword"), keyword)
+	in.env.Set(Symbol("vector"), vector)
+	in.env.Set(Symbol("apply"), apply)
+	in.env.Set(Symbol("map"), mapCF)
+	in.env.Set(Symbol("reset!"), resetCF)
+	in.env.Set(Symbol("swap!"), swap)
+	in.env.Set(Symbol("number?"), numberq)
+	in.env.Set(Symbol("string?"), stringq)
+	in.env.Set(Symbol("fn?"), fnq)
+	in.env.Set(Symbol("macro?"), macroq)
+	in.env.Set(Symbol("hash-map"), hashMap)
+	in.env.Set(Symbol("assoc"), assoc)
+	in.env.Set(Symbol("dissoc"), dissoc)
+	in.env.Set(Symbol("get"), get)
+	in.env.Set(Symbol("contains?"), containsq)
+	in.env.Set(Symbol("keys"), keys)
+	in.env.Set(Symbol("vals"), vals)
+	in.env.Set(Symbol("conj"), conj)
+	in.env.Set(Symbol("seq"), seq)
+	in.env.Set(Symbol("meta"), meta)
+	in.env.Set(Symbol("with-meta"), withMeta)
+	in.env.Set(Symbol("time-ms"), timeMs)
+	in.env.Set(Symbol("readline"), readlineCF)
+	// the core functions see their arguments without metadata, except
+	// those which keep some of them as they are, and meta and with-meta
+	keepMeta := map[Symbol]bool{
+		"list": true, "vector": true, "hash-map": true, "atom": true,
+		"reset!": true, "swap!": true, "cons": true, "conj": true,
+		"assoc": true, "apply": true, "meta": true, "with-meta": true,
+	}
+	for sym, v := range in.env.env {
+		if f, ok := v.(CoreFunc); ok && !keepMeta[sym] {
+			in.env.Set(sym, plainArgs(f))
+		}
+	}
+	in.env.Set(Symbol("*host-language*"), StringLiteral("go2"))
+	in.env.Set(Symbol("*ARGV*"), List{})
+}
+
+// plainArgs makes f see its arguments without their metadata
+func plainArgs(f CoreFunc) CoreFunc {
+	return func(args List, env Env) (SExp, error) {
+		for i, a := range args {
+			if _, ok := a.(WithMeta); ok {
+				plain := append(List{}, args...)
+				for j := i; j < len(plain); j++ {
+					plain[j] = withoutMeta(plain[j])
+				}
+				return f(plain, env)
+			}
+		}
+		return f(args, env)
+	}
+}
+
+func printStrList(sexps List, isReadable bool, sep string) string {
+	s := make([]string, len(sexps))
+	for i, e := range sexps {
+		s[i] = e.PrintStr(isReadable)
+	}
+	return strings.Join(s, sep)
+}
@@ -0,0 +1,103 @@
+package mal
+
+import (
+	"bufio"
+	"io"
+	"strings"
+)
+
+// Interpreter : a mal interpreter. Each Interpreter has its own environment,
+// so several of them can be used independently in one process.
+type Interpreter struct {
+	env    Env
+	stdin  *bufio.Reader
+	stdout io.Writer
+}
+
+// prelude : functions and macros defined in mal itself
+var prelude = []string{
+	"(defmacro! cond (fn* (& xs) (if (> (count xs) 0) (list 'if (first xs) (if (> (count xs) 1) (nth xs 1) (throw \"odd number of forms to cond\")) (cons 'cond (rest (rest xs)))))))",
+	"(def! *gensym-counter* (atom 0))",
+	"(def! gensym (fn* [] (symbol (str \"G__\" (swap! *gensym-counter* (fn* [x] (+ 1 x)))))))",
+	"(defmacro! or (fn* (& xs) (if (empty? xs) nil (if (= 1 (count xs)) (first xs) (let* (condvar (gensym)) `(let* (~condvar ~(first xs)) (if ~condvar ~condvar (or ~@(rest xs)))))))))",
+}
+
+// NewInterpreter makes an Interpreter. readline reads from stdin, and prn and println write to stdout.
+func NewInterpreter(stdin io.Reader, stdout io.Writer) *Interpreter {
+	in := &Interpreter{
+		stdin:  bufio.NewReader(stdin),
+		stdout: stdout,
+	}
+	in.initREPLEnv()
+	for _, s := range prelude {
+		if _, err := in.Eval(s); err != nil {
+			panic(err)
+		}
+	}
+	return in
+}
+
+// Eval reads and evaluates every form in src, and returns the value of the last one.
+// It returns UNDEF if src has no form.
+func (in *Interpreter) Eval(src string) (SExp, error) {
+	r := initReader(src)
+	var val SExp = UNDEF
+	for !r.isReachedEND {
+		sexp, err := r.readForm()
+		if err != nil {
+			return UNDEF, err
+		}
+		if sexp == UNDEF {
+			break
+		}
+		val, err = sexp.Eval(in.env)
+		if err != nil {
+			return UNDEF, err
+		}
+	}
+	return val, nil
+}
+
+// Rep evaluates src and returns the printed value
+func (in *Interpreter) Rep(src string) (string, error) {
+	v, err := in.Eval(src)
+	if err != nil {
+		return "", err
+	}
+	return v.PrintStr(true), nil
+}
+
+// Run loads the script file with *ARGV* bound to args
+func (in *Interpreter) Run(file string, args []string) error {
+	argv := make(List, 0, len(args))
+	for _, a := range args {
+		argv = append(argv, StringLiteral(a))
+	}
+	in.Set("*ARGV*", argv)
+	loadFile, _ := in.env.Get(Symbol("load-file"))
+	_, err := applyFunc(loadFile, List{StringLiteral(file)}, in.env)
+	return err
+}
+
+// Set defines name in the top level environment
+func (in *Interpreter) Set(name string, v SExp) {
+	in.env.Set(Symbol(name), v)
+}
+
+// Get returns the value of name in the top level environment
+func (in *Interpreter) Get(name string) (SExp, bool) {
+	return in.env.Get(Symbol(name))
+}
+
+// ReadLine prints prompt and reads a line from stdin. It returns false at EOF.
+func (in *Interpreter) ReadLine(prompt string) (string, bool) {
+	io.WriteString(in.stdout, prompt)
+	line, err := in.stdin.ReadString('\n')
+	if err == io.EOF && len(line) > 0 {
+		err = nil
+	}
+	if err != nil {
+		return "", false
+	}
+	return strings.TrimRight(line, "\r\n"), true
+}
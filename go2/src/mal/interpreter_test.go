@@ -0,0 +1,110 @@
+package mal
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+)
+
+func TestEval(test *testing.T) {
+	in := NewInterpreter(strings.NewReader(""), &bytes.Buffer{})
+	check := func(src, expected string) {
+		s, err := in.Rep(src)
+		if err != nil {
+			test.Errorf("%s: %v", src, err)
+		} else if s != expected {
+			test.Errorf("%s\nExpected: %v\nbut actually got: %v\n", src, expected, s)
+		}
+	}
+
+	check("(+ 1 2)", "3")
+	check("(def! x 10) (* x 2)", "20")
+	check("(let* [y 1] (or nil y))", "1")
+	check(`(str "a" :b)`, `"a:b"`)
+	check("; nothing", "*Undefined*")
+
+	if _, err := in.Eval("(throw {:a 1})"); err == nil {
+		test.Error("throw should return an error")
+	} else if e, ok := err.(Exception); !ok || !e.value.Equal(HashMap{Keyword("a"): Int(1)}) {
+		test.Errorf("unexpected error: %v", err)
+	}
+}
+
+func TestIndependentInterpreters(test *testing.T) {
+	out1, out2 := &bytes.Buffer{}, &bytes.Buffer{}
+	in1 := NewInterpreter(strings.NewReader(""), out1)
+	in2 := NewInterpreter(strings.NewReader(""), out2)
+
+	if _, err := in1.Eval("(def! x 1) (prn x)"); err != nil {
+		test.Fatal(err)
+	}
+	if _, err := in2.Eval("x"); err == nil {
+		test.Error("x defined in one interpreter should not be seen by another")
+	}
+	if _, err := in2.Eval(`(println "two")`); err != nil {
+		test.Fatal(err)
+	}
+	if out1.String() != "1\n" || out2.String() != "two\n" {
+		test.Errorf("unexpected outputs: %q, %q", out1.String(), out2.String())
+	}
+}
+
+func TestReadLine(test *testing.T) {
+	out := &bytes.Buffer{}
+	in := NewInterpreter(strings.NewReader("hello\nworld"), out)
+	check := func(expected string) {
+		s, err := in.Rep(`(readline "> ")`)
+		if err != nil {
+			test.Error(err)
+		} else if s != expected {
+			test.Errorf("Expected: %v\nbut actually got: %v\n", expected, s)
+		}
+	}
+	check(`"hello"`)
+	check(`"world"`)
+	check("nil")
+	if out.String() != "> > > " {
+		test.Errorf("unexpected prompts: %q", out.String())
+	}
+}
+
+func TestErrors(test *testing.T) {
+	in := NewInterpreter(strings.NewReader(""), &bytes.Buffer{})
+	check := func(src, expected string) {
+		_, err := in.Eval(src)
+		if err == nil {
+			test.Errorf("%s: expected an error", src)
+		} else if err.Error() != expected {
+			test.Errorf("%s\nExpected: %v\nbut actually got: %v\n", src, expected, err)
+		}
+	}
+
+	check(`(- "a" 1)`, `-'s arguments should be Int ... but got "a"`)
+	check("(/ nil 2)", "/'s arguments should be Int ... but got nil")
+	check("(/ 1 0)", "division by zero")
+	check("(-)", "'(- INT INT ...)'")
+	check("(< 1)", "'(< INT INT)'")
+	check("((fn* (a b) a) 1)", "fn* takes 2 arguments, but got 1")
+	check("((fn* (a b) a) 1 2 3)", "fn* takes 2 arguments, but got 3")
+	check("((fn* (a & b) a))", "fn* takes at least 1 arguments, but got 0")
+	check("(fn* (a & b c) a)", "fn*'s '&' should be followed by exactly one SYMBOL")
+	check("(fn* (a))", "'(fn* (SYMBOL ...) EXP)'")
+	check(`"a\qb"`, `unknown escape sequence '\q' in string literal`)
+	check(`(read-string "\"a\\qb\"")`, `unknown escape sequence '\q' in string literal`)
+	check(`"abc`, `expected '"', got EOF`)
+	check("(def! x)", "'(def! SYMBOL EXP)'")
+	check("(if)", "'(if COND THEN ELSE)'")
+	check("(let* (a 1))", "'(let* (SYMBOL EXP ...) EXP)'")
+	check("(quasiquote (1 (splice-unquote)))", "'(splice-unquote EXP)'")
+	check("(count 1)", "count's argument should be List, Vector or HashMap")
+	check("(first 1)", "first's argument should be List, Vector or nil")
+	check("(deref 1)", "deref's argument should be Atom")
+	check("(atom)", "'(atom EXP)'")
+	check("(map + 1)", "map's second argument should be List or Vector")
+	check("(nth {} 0)", "nth's first argument should be List or Vector")
+
+	// errors are catchable
+	if s, err := in.Rep(`(try* (/ 1 0) (catch* e e))`); err != nil || s != `"division by zero"` {
+		test.Errorf("unexpected result: %v, %v", s, err)
+	}
+}
@@ -21,10 +21,9 @@ func main() {
 	for scanner.Scan() {
 		s := scanner.Text()
 		s = strings.TrimRight(s, "\n")
-		if len(s) == 0 {
-			break
+		if len(s) != 0 {
+			print(eval(s))
 		}
-		print(eval(s))
 		fmt.Print("user> ")
 	}
 }
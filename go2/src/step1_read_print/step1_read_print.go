@@ -3,14 +3,27 @@ package main
 import (
 	"bufio"
 	"fmt"
+	"io"
 	"os"
+	"strings"
 )
 
-func read(scanner *bufio.Scanner) (SExp, error) {
-	s := scanner.Text()
-	if len(s) == 0 {
-		os.Exit(0)
+var stdin = bufio.NewReader(os.Stdin)
+
+// readLine prints prompt and reads a line from stdin. It returns false at EOF.
+func readLine(prompt string) (string, bool) {
+	fmt.Print(prompt)
+	line, err := stdin.ReadString('\n')
+	if err == io.EOF && len(line) > 0 {
+		err = nil
+	}
+	if err != nil {
+		return "", false
 	}
+	return strings.TrimRight(line, "\r\n"), true
+}
+
+func read(s string) (SExp, error) {
 	r := initReader(s)
 	return r.readForm()
 }
@@ -27,15 +40,16 @@ func print(e SExp) {
 }
 
 func main() {
-	scanner := bufio.NewScanner(os.Stdin)
-	fmt.Print("user> ")
-	for scanner.Scan() {
-		s, err := read(scanner)
+	for {
+		line, ok := readLine("user> ")
+		if !ok {
+			break
+		}
+		s, err := read(line)
 		if err != nil {
 			fmt.Println(err)
-		} else {
+		} else if s != UNDEF { // UNDEF means empty input
 			print(eval(s))
 		}
-		fmt.Print("user> ")
 	}
 }
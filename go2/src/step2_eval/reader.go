@@ -159,6 +159,9 @@ func (r *Reader) readForm() (SExp, error) {
 		if e != nil {
 			return nil, e
 		}
+		if s == UNDEF {
+			return UNDEF, errors.New("expected form, got EOF")
+		}
 		qd := make([]SExp, 2)
 		switch t {
 		case QUOTE:
@@ -180,6 +183,9 @@ func (r *Reader) readForm() (SExp, error) {
 		if e != nil {
 			return nil, e
 		}
+		if s == UNDEF {
+			return UNDEF, errors.New("expected form, got EOF")
+		}
 		qd := make([]SExp, 3)
 		qd[0] = Symbol("with-meta")
 		qd[2] = s
@@ -187,6 +193,9 @@ func (r *Reader) readForm() (SExp, error) {
 		if e != nil {
 			return nil, e
 		}
+		if s == UNDEF {
+			return UNDEF, errors.New("expected form, got EOF")
+		}
 		qd[1] = s
 		return List(qd), nil
 	case "":
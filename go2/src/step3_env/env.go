@@ -1,5 +1,7 @@
 package main
 
+import "errors"
+
 type envInternal map[Symbol]SExp
 
 func makeEnvInternal() envInternal { return make(envInternal) }
@@ -31,6 +33,19 @@ func makeNewEnv(e Env) Env {
 
 var replEnv Env
 
+// ints converts the arguments of the function name to ints
+func ints(name string, args List) ([]int, error) {
+	ret := make([]int, len(args))
+	for i, v := range args {
+		n, ok := v.(Int)
+		if !ok {
+			return nil, errors.New(name + "'s arguments should be Int ... but got " + v.toString())
+		}
+		ret[i] = int(n)
+	}
+	return ret, nil
+}
+
 func init() {
 	replEnv = Env{
 		env:     makeEnvInternal(),
@@ -38,62 +53,67 @@ func init() {
 	}
 	plus := Closure{
 		env: replEnv,
-		fun: Func(func(_ Env, args List) SExp {
+		fun: Func(func(_ Env, args List) (SExp, error) {
+			xs, err := ints("+", args)
+			if err != nil {
+				return UNDEF, err
+			}
 			s := 0
-			for _, v := range args {
-				switch v.(type) {
-				case Int:
-					s += int(v.(Int))
-				default:
-					panic("+ expects int, but got ...")
-				}
+			for _, x := range xs {
+				s += x
 			}
-			return Int(s)
+			return Int(s), nil
 		}),
 	}
 	minus := Closure{
 		env: replEnv,
-		fun: Func(func(_ Env, args List) SExp {
-			s := int(args[0].(Int))
-			for _, v := range args[1:] {
-				switch v.(type) {
-				case Int:
-					s -= int(v.(Int))
-				default:
-					panic("- expects int, but got ...")
-				}
+		fun: Func(func(_ Env, args List) (SExp, error) {
+			if len(args) == 0 {
+				return UNDEF, errors.New("'(- INT INT ...)'")
 			}
-			return Int(s)
+			xs, err := ints("-", args)
+			if err != nil {
+				return UNDEF, err
+			}
+			s := xs[0]
+			for _, x := range xs[1:] {
+				s -= x
+			}
+			return Int(s), nil
 		}),
 	}
 	times := Closure{
 		env: replEnv,
-		fun: Func(func(_ Env, args List) SExp {
+		fun: Func(func(_ Env, args List) (SExp, error) {
+			xs, err := ints("*", args)
+			if err != nil {
+				return UNDEF, err
+			}
 			s := 1
-			for _, v := range args {
-				switch v.(type) {
-				case Int:
-					s *= int(v.(Int))
-				default:
-					panic("* expects int, but got ...")
-				}
+			for _, x := range xs {
+				s *= x
 			}
-			return Int(s)
+			return Int(s), nil
 		}),
 	}
 	div := Closure{
 		env: replEnv,
-		fun: Func(func(_ Env, args List) SExp {
-			s := int(args[0].(Int))
-			for _, v := range args[1:] {
-				switch v.(type) {
-				case Int:
-					s /= int(v.(Int))
-				default:
-					panic("/ expects int, but got ...")
+		fun: Func(func(_ Env, args List) (SExp, error) {
+			if len(args) == 0 {
+				return UNDEF, errors.New("'(/ INT INT ...)'")
+			}
+			xs, err := ints("/", args)
+			if err != nil {
+				return UNDEF, err
+			}
+			s := xs[0]
+			for _, x := range xs[1:] {
+				if x == 0 {
+					return UNDEF, errors.New("division by zero")
 				}
+				s /= x
 			}
-			return Int(s)
+			return Int(s), nil
 		}),
 	}
 	replEnv.set(Symbol("+"), plus)
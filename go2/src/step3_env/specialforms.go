@@ -2,16 +2,11 @@ package main
 
 import "errors"
 
-const IF = "if"
-const COND = "cond"
-const OR = "or"
 const DEF = "def!"
-const DEFMACRO = "defmacro!"
 const LET = "let*"
 
 var specialFormMap = map[string]struct{}{
-	IF: struct{}{}, COND: struct{}{}, OR: struct{}{},
-	DEF: struct{}{}, DEFMACRO: struct{}{}, LET: struct{}{},
+	DEF: struct{}{}, LET: struct{}{},
 }
 
 func isSpecialForm(s SExp) (string, bool) {
@@ -25,6 +20,9 @@ func isSpecialForm(s SExp) (string, bool) {
 }
 
 func evalDef(env Env, l List) (SExp, error) {
+	if len(l) != 2 {
+		return UNDEF, errors.New("'(def! SYMBOL EXP)'")
+	}
 	switch l[0].(type) {
 	case Symbol:
 		s := l[0].(Symbol)
@@ -40,33 +38,28 @@ func evalDef(env Env, l List) (SExp, error) {
 }
 
 func evalLet(env Env, l List) (SExp, error) {
-	switch l[0].(type) {
+	if len(l) != 2 {
+		return UNDEF, errors.New("'(let* (SYMBOL EXP ...) EXP)'")
+	}
+	var vars List
+	switch v := l[0].(type) {
 	case List:
-		vars := l[0].(List)
-		body := l[1]
-		tmpEnv := makeNewEnv(env)
-		if len(vars)%2 != 0 {
-			return UNDEF, errors.New("Syntax Error: let*'s bind")
-		}
-		for i := 0; i < len(vars); i += 2 {
-			evalLetBindOne(tmpEnv, vars[i:i+2])
-		}
-		return body.eval(tmpEnv)
-
+		vars = v
 	case Vector:
-		vars := l[0].(Vector).toList()
-		body := l[1]
-		tmpEnv := makeNewEnv(env)
-		if len(vars)%2 != 0 {
-			return UNDEF, errors.New("Syntax Error: let*'s bind")
-		}
-		for i := 0; i < len(vars); i += 2 {
-			evalLetBindOne(tmpEnv, vars[i:i+2])
-		}
-		return body.eval(tmpEnv)
+		vars = v.toList()
 	default:
 		return UNDEF, errors.New("Syntax error: let*")
 	}
+	if len(vars)%2 != 0 {
+		return UNDEF, errors.New("Syntax Error: let*'s bind")
+	}
+	tmpEnv := makeNewEnv(env)
+	for i := 0; i < len(vars); i += 2 {
+		if err := evalLetBindOne(tmpEnv, vars[i:i+2]); err != nil {
+			return UNDEF, err
+		}
+	}
+	return l[1].eval(tmpEnv)
 }
 
 func evalLetBindOne(env Env, l List) error {
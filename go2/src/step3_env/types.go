@@ -60,37 +60,31 @@ func (l List) toString() string {
 func (l List) eval(env Env) (SExp, error) {
 	if len(l) == 0 {
 		return l, nil
-	} else if len(l) > 1 {
-		if v, ok := isSpecialForm(l[0]); ok {
-			switch v {
-			case IF:
-			case COND:
-			case OR:
-			case DEF:
-				return evalDef(env, l[1:])
-			case DEFMACRO:
-			case LET:
-				return evalLet(env, l[1:])
-			default:
-				panic("can't reach here... eval special form")
-			}
+	}
+	if v, ok := isSpecialForm(l[0]); ok {
+		switch v {
+		case DEF:
+			return evalDef(env, l[1:])
+		case LET:
+			return evalLet(env, l[1:])
 		}
-		switch c, err := l[0].eval(env); c.(type) {
-		case Closure: // apply
-			args := make(List, len(l)-1)
-			for i, elem := range l[1:] {
-				args[i], err = elem.eval(env)
-				if err != nil {
-					return UNDEF, err
-				}
-			}
-
-			return c.(Closure).apply(args), nil
-		default:
-			println("error: can't apply")
+	}
+	c, err := l[0].eval(env)
+	if err != nil {
+		return UNDEF, err
+	}
+	f, ok := c.(Closure)
+	if !ok {
+		return UNDEF, errors.New("can't apply " + l.toString())
+	}
+	args := make(List, len(l)-1)
+	for i, elem := range l[1:] {
+		args[i], err = elem.eval(env)
+		if err != nil {
+			return UNDEF, err
 		}
 	}
-	return UNDEF, errors.New("..........")
+	return f.apply(args)
 }
 
 // Vector : e.g. [1 2 3]
@@ -136,7 +130,7 @@ func (hm HashMap) eval(env Env) (SExp, error) {
 }
 
 // Func : function
-type Func func(env Env, args List) SExp
+type Func func(env Env, args List) (SExp, error)
 
 // Closure : function + environment
 type Closure struct {
@@ -147,7 +141,7 @@ type Closure struct {
 func (c Closure) toString() string           { return "*Closure*" }
 func (c Closure) eval(env Env) (SExp, error) { return c, nil }
 
-func (c Closure) apply(args List) SExp { return c.fun(c.env, args) }
+func (c Closure) apply(args List) (SExp, error) { return c.fun(c.env, args) }
 
 func toStringSexpSlice(ls string, sexps []SExp, rs string) string {
 	t := make([]byte, 0, 10)
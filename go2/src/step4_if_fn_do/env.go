@@ -2,6 +2,7 @@ package main
 
 import (
 	"errors"
+	"fmt"
 	"strings"
 )
 
@@ -9,7 +10,7 @@ type envInternal map[Symbol]SExp
 
 func makeEnvInternal() envInternal { return make(envInternal) }
 
-// Env : Environment
+// Env : Environment. Env is shared by reference, so a closure sees definitions made after it was created
 type Env struct {
 	env     envInternal
 	nextEnv *Env
@@ -38,19 +39,6 @@ func makeNewEnv(e Env) Env {
 	}
 }
 
-func (e Env) copy() Env {
-	var ne Env
-	if e.nextEnv == nil {
-		ne.env = make(envInternal)
-	} else {
-		ne = e.nextEnv.copy()
-	}
-	for k, v := range e.env {
-		ne.env[k] = v.copy()
-	}
-	return ne
-}
-
 var replEnv Env
 
 func init() {
@@ -73,6 +61,12 @@ func init() {
 		})
 	minus := CoreFunc(
 		func(args List) (SExp, error) {
+			if len(args) == 0 {
+				return UNDEF, errors.New("'(- INT INT ...)'")
+			}
+			if _, ok := args[0].(Int); !ok {
+				return UNDEF, errors.New("invalid -'s argument")
+			}
 			s := int(args[0].(Int))
 			for _, v := range args[1:] {
 				switch v.(type) {
@@ -99,13 +93,22 @@ func init() {
 		})
 	div := CoreFunc(
 		func(args List) (SExp, error) {
+			if len(args) == 0 {
+				return UNDEF, errors.New("'(/ INT INT ...)'")
+			}
+			if _, ok := args[0].(Int); !ok {
+				return UNDEF, errors.New("invalid /'s argument")
+			}
 			s := int(args[0].(Int))
 			for _, v := range args[1:] {
 				switch v.(type) {
 				case Int:
+					if v.(Int) == 0 {
+						return UNDEF, errors.New("division by zero")
+					}
 					s /= int(v.(Int))
 				default:
-					return UNDEF, errors.New("invalid +'s argument")
+					return UNDEF, errors.New("invalid /'s argument")
 				}
 			}
 			return Int(s), nil
@@ -141,6 +144,9 @@ func init() {
 		})
 	listq := CoreFunc(
 		func(args List) (SExp, error) {
+			if len(args) != 1 {
+				return UNDEF, errors.New("type predicates take 1 argument")
+			}
 			switch args[0].(type) {
 			case List:
 				return Bool(true), nil
@@ -150,6 +156,9 @@ func init() {
 		})
 	emptyq := CoreFunc(
 		func(args List) (SExp, error) {
+			if len(args) != 1 {
+				return UNDEF, errors.New("'(empty? LIST)'")
+			}
 			switch args[0].(type) {
 			case List:
 				return Bool(len(args[0].(List)) == 0), nil
@@ -161,6 +170,9 @@ func init() {
 		})
 	count := CoreFunc(
 		func(args List) (SExp, error) {
+			if len(args) != 1 {
+				return UNDEF, errors.New("'(count LIST)'")
+			}
 			switch args[0].(type) {
 			case List:
 				return Int(len(args[0].(List))), nil
@@ -171,6 +183,9 @@ func init() {
 			}
 		})
 	not := CoreFunc(func(args List) (SExp, error) {
+		if len(args) != 1 {
+			return UNDEF, errors.New("'(not EXP)'")
+		}
 		b := true
 		switch args[0].(type) {
 		case NilType:
@@ -185,6 +200,9 @@ func init() {
 	})
 	do := CoreFunc(
 		func(args List) (SExp, error) {
+			if len(args) == 0 {
+				return NIL, nil
+			}
 			return args[len(args)-1], nil
 		})
 	prstr := CoreFunc(
@@ -194,7 +212,7 @@ func init() {
 		})
 	prn := CoreFunc(
 		func(args List) (SExp, error) {
-			println(printStrList(args, true, " "))
+			fmt.Println(printStrList(args, true, " "))
 			return NIL, nil
 		})
 	str := CoreFunc(
@@ -207,7 +225,7 @@ func init() {
 		})
 	printlnCF := CoreFunc(
 		func(args List) (SExp, error) {
-			println(printStrList(args, false, " "))
+			fmt.Println(printStrList(args, false, " "))
 			return NIL, nil
 		})
 	replEnv.set(Symbol("+"), plus)
@@ -27,6 +27,9 @@ func isSpecialForm(s SExp) (string, bool) {
 }
 
 func evalIf(env Env, l List) (SExp, error) {
+	if len(l) != 2 && len(l) != 3 {
+		return UNDEF, errors.New("'(if COND THEN ELSE)'")
+	}
 	cond := true
 	c, err := l[0].eval(env)
 	if err != nil {
@@ -48,6 +51,9 @@ func evalIf(env Env, l List) (SExp, error) {
 }
 
 func evalDef(env Env, l List) (SExp, error) {
+	if len(l) != 2 {
+		return UNDEF, errors.New("'(def! SYMBOL EXP)'")
+	}
 	switch l[0].(type) {
 	case Symbol:
 		s := l[0].(Symbol)
@@ -56,23 +62,6 @@ func evalDef(env Env, l List) (SExp, error) {
 			return UNDEF, err
 		}
 		env.set(s, v)
-
-		// if def! defines a recursive function,...
-		switch l := l[1].(type) {
-		case List:
-			switch t := l[0].(type) {
-			case Symbol:
-				if t == Symbol(FN) {
-					switch v := v.(type) {
-					case Closure:
-						v.env.set(s, v)
-						v.name = s
-					default:
-						panic("can't reach here")
-					}
-				}
-			}
-		}
 		return v, nil
 	default:
 		return UNDEF, errors.New("'(def! SYMBOL EXP)'")
@@ -80,6 +69,9 @@ func evalDef(env Env, l List) (SExp, error) {
 }
 
 func evalLet(env Env, l List) (SExp, error) {
+	if len(l) != 2 {
+		return UNDEF, errors.New("'(let* (SYMBOL EXP ...) EXP)'")
+	}
 	switch l[0].(type) {
 	case List:
 		vars := l[0].(List)
@@ -125,6 +117,9 @@ func evalLetBindOne(env Env, l List) error {
 }
 
 func evalFn(env Env, l List) (SExp, error) {
+	if len(l) != 2 {
+		return UNDEF, errors.New("'(fn* (SYMBOL ...) EXP)'")
+	}
 	var params []SExp
 	switch l[0].(type) {
 	case List:
@@ -132,7 +127,7 @@ func evalFn(env Env, l List) (SExp, error) {
 	case Vector:
 		params = l[0].(Vector)
 	default:
-		return UNDEF, errors.New("unimplemented")
+		return UNDEF, errors.New("fn*'s parameters should be List or Vector ... but got " + l[0].toString())
 	}
 	cparams := make([]Symbol, len(params))
 	for i, p := range params {
@@ -142,9 +137,12 @@ func evalFn(env Env, l List) (SExp, error) {
 		default:
 			return UNDEF, errors.New("fn* param should be SYMBOL ... but got " + p.toString())
 		}
+		if string(cparams[i]) == "&" && i != len(params)-2 {
+			return UNDEF, errors.New("fn*'s '&' should be followed by exactly one SYMBOL")
+		}
 	}
 	return Closure{
-		env:    env.copy(),
+		env:    env,
 		params: cparams,
 		body:   l[1],
 	}, nil
@@ -3,6 +3,7 @@ package main
 import (
 	"errors"
 	"fmt"
+	"sort"
 )
 
 // SExp : a S SExpression
@@ -155,7 +156,9 @@ func (s StringLiteral) escape() StringLiteral {
 	}
 	return StringLiteral(ret)
 }
-func (s StringLiteral) unescape() StringLiteral {
+
+// unescape interprets the escape sequences of a string literal
+func (s StringLiteral) unescape() (StringLiteral, error) {
 	str := string(s)
 	ret := ""
 	bs := false
@@ -170,7 +173,7 @@ func (s StringLiteral) unescape() StringLiteral {
 			case '\\':
 				ret += "\\"
 			default:
-				panic("unescape!!!")
+				return "", fmt.Errorf("unknown escape sequence '\\%c' in string literal", r)
 			}
 		} else if r == '\\' {
 			bs = true
@@ -178,7 +181,7 @@ func (s StringLiteral) unescape() StringLiteral {
 			ret += fmt.Sprintf("%c", r)
 		}
 	}
-	return StringLiteral(ret)
+	return StringLiteral(ret), nil
 }
 
 // List : e.g. (1 2 3)
@@ -209,33 +212,27 @@ func (l List) eval(env Env) (SExp, error) {
 		case FN:
 			return evalFn(env, l[1:])
 		default:
-			panic("can't reach here... eval special form")
+			return UNDEF, errors.New("unknown special form " + v)
 		}
 	}
-	switch c, err := l[0].eval(env); c.(type) {
-	case CoreFunc: // apply
-		args := make(List, len(l)-1)
-		for i, elem := range l[1:] {
-			args[i], err = elem.eval(env)
-			if err != nil {
-				return UNDEF, err
-			}
+	c, err := l[0].eval(env)
+	if err != nil {
+		return UNDEF, err
+	}
+	args := make(List, len(l)-1)
+	for i, elem := range l[1:] {
+		args[i], err = elem.eval(env)
+		if err != nil {
+			return UNDEF, err
 		}
-		return c.(CoreFunc).apply(args)
+	}
+	switch c := c.(type) {
+	case CoreFunc:
+		return c.apply(args)
 	case Closure:
-		args := make(List, len(l)-1)
-		for i, elem := range l[1:] {
-			args[i], err = elem.eval(env)
-			if err != nil {
-				return UNDEF, err
-			}
-		}
-		return c.(Closure).apply(args)
-	default:
-		println("error: can't apply")
+		return c.apply(args)
 	}
-
-	return UNDEF, errors.New("eval?")
+	return UNDEF, errors.New("can't apply " + l.toString())
 }
 
 func (l List) copy() SExp {
@@ -308,21 +305,27 @@ func (v Vector) toList() List {
 	return List(v)
 }
 
-// HashMap : {x 1, y 2}ya
-type HashMap []SExp
+// HashMap : {x 1, y 2}. Keys are Keyword, StringLiteral, Int or Symbol
+type HashMap map[SExp]SExp
 
 func (hm HashMap) toString() string {
-	return toStringSexpSlice("{", []SExp(hm), "}", true)
+	return toStringSexpSlice("{", hm.flatten(), "}", true)
 }
 func (hm HashMap) printStr(isReadable bool) string {
-	return toStringSexpSlice("{", []SExp(hm), "}", isReadable)
+	return toStringSexpSlice("{", hm.flatten(), "}", isReadable)
 }
 
 func (hm HashMap) eval(env Env) (SExp, error) {
 	ret := make(HashMap, len(hm))
-	for i, elem := range hm {
-		var err error
-		ret[i], err = elem.eval(env)
+	for key, val := range hm {
+		k, err := key.eval(env)
+		if err != nil {
+			return UNDEF, err
+		}
+		if !isHashKey(k) {
+			return UNDEF, errors.New("invalid HashMap key " + k.toString())
+		}
+		ret[k], err = val.eval(env)
 		if err != nil {
 			return UNDEF, err
 		}
@@ -344,8 +347,9 @@ func (hm HashMap) isSame(s SExp) bool {
 		if len(hm) != len(s) {
 			return false
 		}
-		for i := 0; i < len(hm); i++ {
-			if !hm[i].isSame(s[i]) {
+		for key, val := range hm {
+			v, ok := s[key]
+			if !ok || !val.isSame(v) {
 				return false
 			}
 		}
@@ -354,6 +358,100 @@ func (hm HashMap) isSame(s SExp) bool {
 	return false
 }
 
+// makeHashMap makes a HashMap from alternating keys and values. A later key overrides an earlier one.
+func makeHashMap(kvs []SExp) (HashMap, error) {
+	return HashMap{}.assoc(kvs)
+}
+
+// assoc returns a new HashMap with the key-value pairs in kvs added
+func (hm HashMap) assoc(kvs []SExp) (HashMap, error) {
+	if len(kvs)%2 != 0 {
+		return nil, errors.New("odd number of arguments for a HashMap")
+	}
+	ret := make(HashMap, len(hm)+len(kvs)/2)
+	for key, val := range hm {
+		ret[key] = val
+	}
+	for i := 0; i < len(kvs); i += 2 {
+		if !isHashKey(kvs[i]) {
+			return nil, errors.New("invalid HashMap key " + kvs[i].toString())
+		}
+		ret[kvs[i]] = kvs[i+1]
+	}
+	return ret, nil
+}
+
+// dissoc returns a new HashMap without keys
+func (hm HashMap) dissoc(keys []SExp) HashMap {
+	ret := make(HashMap, len(hm))
+	for key, val := range hm {
+		ret[key] = val
+	}
+	for _, key := range keys {
+		if isHashKey(key) {
+			delete(ret, key)
+		}
+	}
+	return ret
+}
+
+// get returns the value for key
+func (hm HashMap) get(key SExp) (SExp, bool) {
+	if !isHashKey(key) {
+		return UNDEF, false
+	}
+	v, ok := hm[key]
+	return v, ok
+}
+
+// keys returns the keys in the order they are printed
+func (hm HashMap) keys() []SExp {
+	ret := make([]SExp, 0, len(hm))
+	for key := range hm {
+		ret = append(ret, key)
+	}
+	sort.Slice(ret, func(i, j int) bool { return lessHashKey(ret[i], ret[j]) })
+	return ret
+}
+
+func (hm HashMap) flatten() []SExp {
+	ret := make([]SExp, 0, 2*len(hm))
+	for _, key := range hm.keys() {
+		ret = append(ret, key, hm[key])
+	}
+	return ret
+}
+
+func isHashKey(s SExp) bool {
+	switch s.(type) {
+	case Keyword, StringLiteral, Int, Symbol:
+		return true
+	}
+	return false
+}
+
+// lessHashKey orders keys by type (Int, StringLiteral, Keyword, Symbol), then by value
+func lessHashKey(a, b SExp) bool {
+	rank := func(s SExp) int {
+		switch s.(type) {
+		case Int:
+			return 0
+		case StringLiteral:
+			return 1
+		case Keyword:
+			return 2
+		}
+		return 3
+	}
+	if rank(a) != rank(b) {
+		return rank(a) < rank(b)
+	}
+	if x, ok := a.(Int); ok {
+		return x < b.(Int)
+	}
+	return a.printStr(false) < b.printStr(false)
+}
+
 // CoreFunc : function
 type CoreFunc func(args List) (SExp, error)
 
@@ -367,7 +465,6 @@ func (c CoreFunc) apply(args List) (SExp, error) { return c(args) }
 
 // Closure : environment + arg List + body
 type Closure struct {
-	name   Symbol // recursive function use
 	env    Env
 	params []Symbol
 	body   SExp
@@ -380,15 +477,27 @@ func (c Closure) isSame(s SExp) bool         { return false } // Closure isn't c
 func (c Closure) copy() SExp {
 	return c
 }
+
+// apply checks the number of arguments and evaluates the body.
+// evalFn has checked that '&' is followed by exactly one parameter.
 func (c Closure) apply(args List) (SExp, error) {
-	ne := makeNewEnv(c.env)
-	for i, p := range c.params {
-		if string(p) == "&" {
-			ne.set(c.params[i+1], args[i:])
-			break
-		} else {
-			ne.set(p, args[i])
+	n := len(c.params)
+	variadic := n >= 2 && c.params[n-2] == "&"
+	if variadic {
+		n -= 2
+	}
+	if len(args) < n || (!variadic && len(args) > n) {
+		if variadic {
+			return UNDEF, fmt.Errorf("fn* takes at least %d arguments, but got %d", n, len(args))
 		}
+		return UNDEF, fmt.Errorf("fn* takes %d arguments, but got %d", n, len(args))
+	}
+	ne := makeNewEnv(c.env)
+	for i, p := range c.params[:n] {
+		ne.set(p, args[i])
+	}
+	if variadic {
+		ne.set(c.params[n+1], append(List{}, args[n:]...))
 	}
 	return c.body.eval(ne)
 }
@@ -87,22 +87,21 @@ func (r *Reader) peek() (Token, error) {
 		r.isReachedEND = true
 		return "", nil
 	case '~':
-		if r.s[start+1] == '@' {
+		if start+1 < len(r.s) && r.s[start+1] == '@' {
 			return "~@", nil
 		}
 		return "~", nil
 
 	case '"':
 		end := start + 1
-		for r.s[end] != '"' {
+		for end < len(r.s) && r.s[end] != '"' {
 			if r.s[end] == '\\' {
 				end++
 			}
 			end++
-
-			if end >= len(r.s) {
-				return "", errors.New("expected '\"', got EOF")
-			}
+		}
+		if end >= len(r.s) {
+			return "", errors.New("expected '\"', got EOF")
 		}
 		return Token(r.s[start : end+1]), nil
 	}
@@ -159,6 +158,9 @@ func (r *Reader) readForm() (SExp, error) {
 		if e != nil {
 			return nil, e
 		}
+		if s == UNDEF {
+			return UNDEF, errors.New("expected form, got EOF")
+		}
 		qd := make([]SExp, 2)
 		switch t {
 		case QUOTE:
@@ -180,6 +182,9 @@ func (r *Reader) readForm() (SExp, error) {
 		if e != nil {
 			return nil, e
 		}
+		if s == UNDEF {
+			return UNDEF, errors.New("expected form, got EOF")
+		}
 		qd := make([]SExp, 3)
 		qd[0] = Symbol("with-meta")
 		qd[2] = s
@@ -187,6 +192,9 @@ func (r *Reader) readForm() (SExp, error) {
 		if e != nil {
 			return nil, e
 		}
+		if s == UNDEF {
+			return UNDEF, errors.New("expected form, got EOF")
+		}
 		qd[1] = s
 		return List(qd), nil
 	case "":
@@ -224,7 +232,7 @@ func (r *Reader) readSeq(right string) (SExp, error) {
 	case "]":
 		return Vector(l), nil
 	case "}":
-		return HashMap(l), nil
+		return readHashMap(l)
 	default:
 		return UNDEF, errors.New("Invalid 'right' in reader.readSeq")
 	}
@@ -242,7 +250,11 @@ func (r *Reader) readAtom() (SExp, error) {
 		}
 		return Int(i), nil
 	} else if tmp[0] == '"' {
-		return StringLiteral(string(tmp[1 : len(tmp)-1])).unescape(), nil
+		s, err := StringLiteral(string(tmp[1 : len(tmp)-1])).unescape()
+		if err != nil {
+			return UNDEF, err
+		}
+		return s, nil
 	} else if tmp[0] == ':' {
 		return Keyword(tmp[1:]), nil
 	} else if t == "true" {
@@ -254,3 +266,21 @@ func (r *Reader) readAtom() (SExp, error) {
 	}
 	return Symbol(t), nil
 }
+
+// readHashMap makes a HashMap literal. Unlike assoc, a duplicated key is an error.
+func readHashMap(l []SExp) (SExp, error) {
+	if len(l)%2 != 0 {
+		return UNDEF, errors.New("odd number of forms in HashMap literal")
+	}
+	hm := make(HashMap, len(l)/2)
+	for i := 0; i < len(l); i += 2 {
+		if !isHashKey(l[i]) {
+			return UNDEF, errors.New("invalid HashMap key " + l[i].toString())
+		}
+		if _, ok := hm[l[i]]; ok {
+			return UNDEF, errors.New("duplicate key " + l[i].toString() + " in HashMap literal")
+		}
+		hm[l[i]] = l[i+1]
+	}
+	return hm, nil
+}
@@ -9,11 +9,12 @@ const DEF = "def!"
 const DEFMACRO = "defmacro!"
 const LET = "let*"
 const FN = "fn*"
+const DO = "do"
 
 var specialFormMap = map[string]struct{}{
 	IF: struct{}{}, COND: struct{}{}, OR: struct{}{},
 	DEF: struct{}{}, DEFMACRO: struct{}{}, LET: struct{}{},
-	FN: struct{}{},
+	FN: struct{}{}, DO: struct{}{},
 }
 
 func isSpecialForm(s SExp) (string, bool) {
@@ -26,11 +27,15 @@ func isSpecialForm(s SExp) (string, bool) {
 	}
 }
 
-func evalIf(env Env, l List) (SExp, error) {
+// evalIf returns the branch to be evaluated in tail position
+func evalIf(env Env, l List) (SExp, Env, error) {
+	if len(l) != 2 && len(l) != 3 {
+		return UNDEF, env, errors.New("'(if COND THEN ELSE)'")
+	}
 	cond := true
 	c, err := l[0].eval(env)
 	if err != nil {
-		return UNDEF, err
+		return UNDEF, env, err
 	}
 	switch c := c.(type) {
 	case NilType:
@@ -39,15 +44,18 @@ func evalIf(env Env, l List) (SExp, error) {
 		cond = bool(c)
 	}
 	if cond {
-		return l[1].eval(env)
+		return l[1], env, nil
 	} else if len(l) >= 3 {
-		return l[2].eval(env)
+		return l[2], env, nil
 	} else {
-		return NIL, nil
+		return NIL, env, nil
 	}
 }
 
 func evalDef(env Env, l List) (SExp, error) {
+	if len(l) != 2 {
+		return UNDEF, errors.New("'(def! SYMBOL EXP)'")
+	}
 	switch l[0].(type) {
 	case Symbol:
 		s := l[0].(Symbol)
@@ -56,58 +64,36 @@ func evalDef(env Env, l List) (SExp, error) {
 			return UNDEF, err
 		}
 		env.set(s, v)
-
-		// if def! defines a recursive function,...
-		switch l := l[1].(type) {
-		case List:
-			switch t := l[0].(type) {
-			case Symbol:
-				if t == Symbol(FN) {
-					switch v := v.(type) {
-					case Closure:
-						v.env = env
-						v.env.set(s, v)
-						v.name = s
-					default:
-						panic("can't reach here")
-					}
-				}
-			}
-		}
 		return v, nil
 	default:
 		return UNDEF, errors.New("'(def! SYMBOL EXP)'")
 	}
 }
 
-func evalLet(env Env, l List) (SExp, error) {
-	switch l[0].(type) {
+// evalLet binds the variables and returns the body with the new environment
+func evalLet(env Env, l List) (SExp, Env, error) {
+	if len(l) != 2 {
+		return UNDEF, env, errors.New("'(let* (SYMBOL EXP ...) EXP)'")
+	}
+	var vars List
+	switch v := l[0].(type) {
 	case List:
-		vars := l[0].(List)
-		body := l[1]
-		tmpEnv := makeNewEnv(env)
-		if len(vars)%2 != 0 {
-			return UNDEF, errors.New("Syntax Error: let*'s bind")
-		}
-		for i := 0; i < len(vars); i += 2 {
-			evalLetBindOne(tmpEnv, vars[i:i+2])
-		}
-		return body.eval(tmpEnv)
-
+		vars = v
 	case Vector:
-		vars := l[0].(Vector).toList()
-		body := l[1]
-		tmpEnv := makeNewEnv(env)
-		if len(vars)%2 != 0 {
-			return UNDEF, errors.New("Syntax Error: let*'s bind")
-		}
-		for i := 0; i < len(vars); i += 2 {
-			evalLetBindOne(tmpEnv, vars[i:i+2])
-		}
-		return body.eval(tmpEnv)
+		vars = v.toList()
 	default:
-		return UNDEF, errors.New("Syntax error: let*")
+		return UNDEF, env, errors.New("Syntax error: let*")
+	}
+	if len(vars)%2 != 0 {
+		return UNDEF, env, errors.New("Syntax Error: let*'s bind")
+	}
+	tmpEnv := makeNewEnv(env)
+	for i := 0; i < len(vars); i += 2 {
+		if err := evalLetBindOne(tmpEnv, vars[i:i+2]); err != nil {
+			return UNDEF, env, err
+		}
 	}
+	return l[1], tmpEnv, nil
 }
 
 func evalLetBindOne(env Env, l List) error {
@@ -125,7 +111,23 @@ func evalLetBindOne(env Env, l List) error {
 	}
 }
 
+// evalDo evaluates all but the last expression, which is left for the caller
+func evalDo(env Env, l List) (SExp, Env, error) {
+	if len(l) == 0 {
+		return NIL, env, nil
+	}
+	for _, s := range l[:len(l)-1] {
+		if _, err := s.eval(env); err != nil {
+			return UNDEF, env, err
+		}
+	}
+	return l[len(l)-1], env, nil
+}
+
 func evalFn(env Env, l List) (SExp, error) {
+	if len(l) != 2 {
+		return UNDEF, errors.New("'(fn* (SYMBOL ...) EXP)'")
+	}
 	var params []SExp
 	switch l[0].(type) {
 	case List:
@@ -133,7 +135,7 @@ func evalFn(env Env, l List) (SExp, error) {
 	case Vector:
 		params = l[0].(Vector)
 	default:
-		return UNDEF, errors.New("unimplemented")
+		return UNDEF, errors.New("fn*'s parameters should be List or Vector ... but got " + l[0].toString())
 	}
 	cparams := make([]Symbol, len(params))
 	for i, p := range params {
@@ -143,9 +145,12 @@ func evalFn(env Env, l List) (SExp, error) {
 		default:
 			return UNDEF, errors.New("fn* param should be SYMBOL ... but got " + p.toString())
 		}
+		if string(cparams[i]) == "&" && i != len(params)-2 {
+			return UNDEF, errors.New("fn*'s '&' should be followed by exactly one SYMBOL")
+		}
 	}
 	return Closure{
-		env:    env.copy(),
+		env:    env,
 		params: cparams,
 		body:   l[1],
 	}, nil
@@ -2,6 +2,7 @@ package main
 
 import (
 	"errors"
+	"fmt"
 	"io/ioutil"
 	"os"
 	"strings"
@@ -11,7 +12,7 @@ type envInternal map[Symbol]SExp
 
 func makeEnvInternal() envInternal { return make(envInternal) }
 
-// Env : Environment
+// Env : Environment. Env is shared by reference, so a closure sees definitions made after it was created
 type Env struct {
 	env     envInternal
 	nextEnv *Env
@@ -40,19 +41,6 @@ func makeNewEnv(e Env) Env {
 	}
 }
 
-func (e Env) copy() Env {
-	var ne Env
-	if e.nextEnv == nil {
-		ne.env = make(envInternal)
-	} else {
-		ne = e.nextEnv.copy()
-	}
-	for k, v := range e.env {
-		ne.env[k] = v.copy()
-	}
-	return ne
-}
-
 var replEnv Env
 
 func init() {
@@ -75,6 +63,12 @@ func init() {
 		})
 	minus := CoreFunc(
 		func(args List, _ Env) (SExp, error) {
+			if len(args) == 0 {
+				return UNDEF, errors.New("'(- INT INT ...)'")
+			}
+			if _, ok := args[0].(Int); !ok {
+				return UNDEF, errors.New("invalid -'s argument")
+			}
 			s := int(args[0].(Int))
 			for _, v := range args[1:] {
 				switch v.(type) {
@@ -101,13 +95,22 @@ func init() {
 		})
 	div := CoreFunc(
 		func(args List, _ Env) (SExp, error) {
+			if len(args) == 0 {
+				return UNDEF, errors.New("'(/ INT INT ...)'")
+			}
+			if _, ok := args[0].(Int); !ok {
+				return UNDEF, errors.New("invalid /'s argument")
+			}
 			s := int(args[0].(Int))
 			for _, v := range args[1:] {
 				switch v.(type) {
 				case Int:
+					if v.(Int) == 0 {
+						return UNDEF, errors.New("division by zero")
+					}
 					s /= int(v.(Int))
 				default:
-					return UNDEF, errors.New("invalid +'s argument")
+					return UNDEF, errors.New("invalid /'s argument")
 				}
 			}
 			return Int(s), nil
@@ -143,6 +146,9 @@ func init() {
 		})
 	listq := CoreFunc(
 		func(args List, _ Env) (SExp, error) {
+			if len(args) != 1 {
+				return UNDEF, errors.New("type predicates take 1 argument")
+			}
 			switch args[0].(type) {
 			case List:
 				return Bool(true), nil
@@ -152,6 +158,9 @@ func init() {
 		})
 	emptyq := CoreFunc(
 		func(args List, _ Env) (SExp, error) {
+			if len(args) != 1 {
+				return UNDEF, errors.New("'(empty? LIST)'")
+			}
 			switch args[0].(type) {
 			case List:
 				return Bool(len(args[0].(List)) == 0), nil
@@ -163,6 +172,9 @@ func init() {
 		})
 	count := CoreFunc(
 		func(args List, _ Env) (SExp, error) {
+			if len(args) != 1 {
+				return UNDEF, errors.New("'(count LIST)'")
+			}
 			switch args[0].(type) {
 			case List:
 				return Int(len(args[0].(List))), nil
@@ -173,6 +185,9 @@ func init() {
 			}
 		})
 	not := CoreFunc(func(args List, _ Env) (SExp, error) {
+		if len(args) != 1 {
+			return UNDEF, errors.New("'(not EXP)'")
+		}
 		b := true
 		switch args[0].(type) {
 		case NilType:
@@ -185,10 +200,6 @@ func init() {
 		}
 		return Bool(!b), nil
 	})
-	do := CoreFunc(
-		func(args List, _ Env) (SExp, error) {
-			return args[len(args)-1], nil
-		})
 	prstr := CoreFunc(
 		func(args List, _ Env) (SExp, error) {
 			s := printStrList(args, true, " ")
@@ -196,7 +207,7 @@ func init() {
 		})
 	prn := CoreFunc(
 		func(args List, _ Env) (SExp, error) {
-			println(printStrList(args, true, " "))
+			fmt.Println(printStrList(args, true, " "))
 			return NIL, nil
 		})
 	str := CoreFunc(
@@ -209,27 +220,37 @@ func init() {
 		})
 	printlnCF := CoreFunc(
 		func(args List, _ Env) (SExp, error) {
-			println(printStrList(args, false, " "))
+			fmt.Println(printStrList(args, false, " "))
 			return NIL, nil
 		})
 	readString := CoreFunc(
 		func(args List, _ Env) (SExp, error) {
-			s, err := args[0].(StringLiteral)
-			if !err {
-				return NIL, errors.New("invalid read-string arg")
+			if len(args) != 1 {
+				return UNDEF, errors.New("'(read-string STRING)'")
 			}
-			if len(s) == 0 {
-				os.Exit(0)
+			s, ok := args[0].(StringLiteral)
+			if !ok {
+				return NIL, errors.New("invalid read-string arg")
 			}
 			r := initReader(string(s))
-			return r.readForm()
+			sexp, err := r.readForm()
+			if err == nil && sexp == UNDEF { // nothing to read
+				return NIL, nil
+			}
+			return sexp, err
 		})
 	evalCore := CoreFunc(
 		func(args List, env Env) (SExp, error) {
+			if len(args) != 1 {
+				return UNDEF, errors.New("'(eval EXP)'")
+			}
 			return args[0].eval(env)
 		})
 	slurp := CoreFunc(
 		func(args List, env Env) (SExp, error) {
+			if len(args) != 1 {
+				return UNDEF, errors.New("'(slurp FILENAME)'")
+			}
 			fn, ok := args[0].(StringLiteral)
 			if !ok {
 				return NIL, errors.New("invalid slurp arg")
@@ -247,6 +268,9 @@ func init() {
 		})
 	loadFile := CoreFunc(
 		func(args List, env Env) (SExp, error) {
+			if len(args) != 1 {
+				return UNDEF, errors.New("'(load-file FILENAME)'")
+			}
 			s, err := slurp.apply(args, env)
 			if err != nil {
 				return NIL, err
@@ -274,26 +298,64 @@ func init() {
 		})
 	atom := CoreFunc(
 		func(args List, env Env) (SExp, error) {
-			return Atom{
+			if len(args) != 1 {
+				return UNDEF, errors.New("'(atom EXP)'")
+			}
+			return &Atom{
 				ref: args[0],
 			}, nil
 		})
 	atomq := CoreFunc(
 		func(args List, env Env) (SExp, error) {
+			if len(args) != 1 {
+				return UNDEF, errors.New("type predicates take 1 argument")
+			}
 			switch args[0].(type) {
-			case Atom:
+			case *Atom:
 				return Bool(true), nil
 			}
 			return Bool(false), nil
 		})
 	deref := CoreFunc(
 		func(args List, env Env) (SExp, error) {
+			if len(args) != 1 {
+				return UNDEF, errors.New("'(deref ATOM)'")
+			}
 			switch a := args[0].(type) {
-			case Atom:
+			case *Atom:
 				return a.ref, nil
 			}
 			return NIL, nil
 		})
+	resetCF := CoreFunc(
+		func(args List, env Env) (SExp, error) {
+			if len(args) != 2 {
+				return UNDEF, errors.New("'(reset! ATOM EXP)'")
+			}
+			a, ok := args[0].(*Atom)
+			if !ok {
+				return UNDEF, errors.New("reset!'s first argument should be Atom")
+			}
+			a.ref = args[1]
+			return a.ref, nil
+		})
+	swap := CoreFunc(
+		func(args List, env Env) (SExp, error) {
+			if len(args) < 2 {
+				return UNDEF, errors.New("'(swap! ATOM FUNC ARGS...)'")
+			}
+			a, ok := args[0].(*Atom)
+			if !ok {
+				return UNDEF, errors.New("swap!'s first argument should be Atom")
+			}
+			fargs := append(List{a.ref}, args[2:]...)
+			v, err := applyFunc(args[1], fargs, env)
+			if err != nil {
+				return UNDEF, err
+			}
+			a.ref = v
+			return v, nil
+		})
 	replEnv.set(Symbol("+"), plus)
 	replEnv.set(Symbol("-"), minus)
 	replEnv.set(Symbol("*"), times)
@@ -308,7 +370,6 @@ func init() {
 	replEnv.set(Symbol("empty?"), emptyq)
 	replEnv.set(Symbol("count"), count)
 	replEnv.set(Symbol("not"), not)
-	replEnv.set(Symbol("do"), do)
 	replEnv.set(Symbol("prn"), prn)
 	replEnv.set(Symbol("str"), str)
 	replEnv.set(Symbol("pr-str"), prstr)
@@ -320,6 +381,9 @@ func init() {
 	replEnv.set(Symbol("atom"), atom)
 	replEnv.set(Symbol("atom?"), atomq)
 	replEnv.set(Symbol("deref"), deref)
+	replEnv.set(Symbol("*ARGV*"), List{})
+	replEnv.set(Symbol("reset!"), resetCF)
+	replEnv.set(Symbol("swap!"), swap)
 }
 
 func printStrList(sexps List, isReadable bool, sep string) string {
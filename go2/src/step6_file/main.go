@@ -3,25 +3,33 @@ package main
 import (
 	"bufio"
 	"fmt"
+	"io"
 	"os"
+	"strings"
 )
 
-func read(scanner *bufio.Scanner) (SExp, error) {
-	s := scanner.Text()
-	if len(s) == 0 {
-		os.Exit(0)
+var stdin = bufio.NewReader(os.Stdin)
+
+// readLine prints prompt and reads a line from stdin. It returns false at EOF.
+func readLine(prompt string) (string, bool) {
+	fmt.Print(prompt)
+	line, err := stdin.ReadString('\n')
+	if err == io.EOF && len(line) > 0 {
+		err = nil
+	}
+	if err != nil {
+		return "", false
 	}
+	return strings.TrimRight(line, "\r\n"), true
+}
+
+func read(s string) (SExp, error) {
 	r := initReader(s)
 	return r.readForm()
 }
 
-func eval(e SExp) SExp {
-	exp, err := e.eval(replEnv)
-	if err != nil {
-		println(err.Error())
-		return UNDEF
-	}
-	return exp
+func eval(e SExp) (SExp, error) {
+	return e.eval(replEnv)
 }
 
 func print(e SExp) {
@@ -29,15 +37,37 @@ func print(e SExp) {
 }
 
 func main() {
-	scanner := bufio.NewScanner(os.Stdin)
-	fmt.Print("user> ")
-	for scanner.Scan() {
-		s, err := read(scanner)
+	// called with mal script to load and eval
+	if len(os.Args) > 1 {
+		args := make(List, 0, len(os.Args)-2)
+		for _, a := range os.Args[2:] {
+			args = append(args, StringLiteral(a))
+		}
+		replEnv.set(Symbol("*ARGV*"), args)
+		loadFile, _ := replEnv.get(Symbol("load-file"))
+		if _, err := applyFunc(loadFile, List{StringLiteral(os.Args[1])}, replEnv); err != nil {
+			fmt.Println("Error: " + err.Error())
+			os.Exit(1)
+		}
+		os.Exit(0)
+	}
+
+	for {
+		line, ok := readLine("user> ")
+		if !ok {
+			break
+		}
+		s, err := read(line)
+		if err == nil && s == UNDEF { // UNDEF means empty input
+			continue
+		}
+		if err == nil {
+			s, err = eval(s)
+		}
 		if err != nil {
-			fmt.Println(err)
+			fmt.Println("Error: " + err.Error())
 		} else {
-			print(eval(s))
+			print(s)
 		}
-		fmt.Print("user> ")
 	}
 }
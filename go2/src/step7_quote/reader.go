@@ -0,0 +1,292 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// Reader is a reader
+type Reader struct {
+	s            []rune
+	pos          int
+	isReachedEND bool
+}
+
+// Token is the type of tokens
+type Token string
+
+const (
+	// QUOTE 'x => x
+	QUOTE = "'"
+	// QUASIQUOTE `x  => (quasiquote x)
+	QUASIQUOTE = "`"
+	// UNQUOTE ~x => (unquote x)
+	UNQUOTE = "~"
+	// SPLICEUNQUOTE ~@x => (splice-unquote x)
+	SPLICEUNQUOTE = "~@"
+	// DEREF @x => (deref x)
+	DEREF = "@"
+	// META ^{a 1} [1 2 3] => (with-meta [1 2 3] {"a" 1})
+	META = "^"
+)
+
+func initReader(s string) *Reader {
+	return &Reader{
+		s:            []rune(s),
+		pos:          0,
+		isReachedEND: false,
+	}
+}
+
+// Next returns the token at the current position and increments the position.
+func (r *Reader) next() (Token, error) {
+	t, err := r.peek()
+
+	if err != nil {
+		return t, err
+	}
+
+	if r.isReachedEND {
+		r.pos = len(r.s)
+		return t, nil
+	}
+
+	r.pos = r.skipBlank(r.pos)
+	r.pos += len([]rune(string(t)))
+
+	return t, nil
+}
+
+// peek returns the toekn at the current position.
+func (r *Reader) peek() (Token, error) {
+	if r.isReachedEND {
+		return "", nil
+	} else if r.pos == len(r.s) {
+		r.isReachedEND = true
+		return "", nil
+	}
+	start := r.skipBlank(r.pos)
+	if start == len(r.s) {
+		r.isReachedEND = true
+		return "", nil
+	}
+
+	switch r.s[start] {
+	case '(', ')', '[', ']', '{', '}', '\'', '`', '@', '^':
+		return runeToToken(r.s[start]), nil
+	case '~':
+		if start+1 < len(r.s) && r.s[start+1] == '@' {
+			return "~@", nil
+		}
+		return "~", nil
+
+	case '"':
+		end := start + 1
+		for end < len(r.s) && r.s[end] != '"' {
+			if r.s[end] == '\\' {
+				end++
+			}
+			end++
+		}
+		if end >= len(r.s) {
+			return "", errors.New("expected '\"', got EOF")
+		}
+		return Token(r.s[start : end+1]), nil
+	}
+
+	end := start
+	for !(isSpecial(r.s[end]) || isSpace(r.s[end])) {
+		end++
+		if end == len(r.s) {
+			break
+		}
+	}
+	return Token(r.s[start:end]), nil
+
+}
+
+// skipBlank returns the position of the first rune from pos which is neither a space nor in a comment
+func (r *Reader) skipBlank(pos int) int {
+	for pos < len(r.s) {
+		if r.s[pos] == ';' {
+			for pos < len(r.s) && r.s[pos] != '\n' {
+				pos++
+			}
+		} else if isSpace(r.s[pos]) {
+			pos++
+		} else {
+			break
+		}
+	}
+	return pos
+}
+
+func runeToString(c rune) string {
+	var t [1]rune
+	t[0] = c
+	return string(t[:])
+}
+
+func runeToToken(c rune) Token {
+	return Token(runeToString(c))
+}
+
+func isSpace(c rune) bool {
+	switch c {
+	case ' ', '\t', '\n', '\r', ',':
+		return true
+	default:
+		return false
+	}
+}
+
+func isSpecial(c rune) bool {
+	return strings.ContainsAny(runeToString(c), "()[]{};\"'`@^")
+}
+
+func (r *Reader) readForm() (SExp, error) {
+	t, err := r.peek()
+	if err != nil {
+		return UNDEF, err
+	}
+	switch t {
+	case "(":
+		return r.readSeq(")")
+	case "[":
+		return r.readSeq("]")
+	case "{":
+		return r.readSeq("}")
+	case QUOTE, QUASIQUOTE, UNQUOTE, SPLICEUNQUOTE, DEREF:
+		_, _ = r.next()
+		s, e := r.readForm()
+		if e != nil {
+			return nil, e
+		}
+		if s == UNDEF {
+			return UNDEF, errors.New("expected form, got EOF")
+		}
+		qd := make([]SExp, 2)
+		switch t {
+		case QUOTE:
+			qd[0] = Symbol("quote")
+		case QUASIQUOTE:
+			qd[0] = Symbol("quasiquote")
+		case UNQUOTE:
+			qd[0] = Symbol("unquote")
+		case SPLICEUNQUOTE:
+			qd[0] = Symbol("splice-unquote")
+		case DEREF:
+			qd[0] = Symbol("deref")
+		}
+		qd[1] = s
+		return List(qd), nil
+	case META:
+		_, _ = r.next()
+		s, e := r.readSeq("}")
+		if e != nil {
+			return nil, e
+		}
+		if s == UNDEF {
+			return UNDEF, errors.New("expected form, got EOF")
+		}
+		qd := make([]SExp, 3)
+		qd[0] = Symbol("with-meta")
+		qd[2] = s
+		s, e = r.readForm()
+		if e != nil {
+			return nil, e
+		}
+		if s == UNDEF {
+			return UNDEF, errors.New("expected form, got EOF")
+		}
+		qd[1] = s
+		return List(qd), nil
+	case "":
+		return UNDEF, nil
+	default:
+		return r.readAtom()
+	}
+}
+
+func (r *Reader) readSeq(right string) (SExp, error) {
+	r.next()
+	l := make([]SExp, 0)
+	for {
+		t, err := r.peek()
+		if err != nil {
+			if strings.HasPrefix(err.Error(), "expected '\"'") {
+				return UNDEF, fmt.Errorf("expected '%s', got EOF", right)
+			}
+		}
+		if t == Token(right) {
+			r.next()
+			break
+		} else if t == "" {
+			return nil, fmt.Errorf("expected '%s', got EOF", right)
+		}
+		h, err := r.readForm()
+		if err != nil {
+			return UNDEF, err
+		}
+		l = append(l, h)
+	}
+	switch right {
+	case ")":
+		return List(l), nil
+	case "]":
+		return Vector(l), nil
+	case "}":
+		return readHashMap(l)
+	default:
+		return UNDEF, errors.New("Invalid 'right' in reader.readSeq")
+	}
+}
+
+func (r *Reader) readAtom() (SExp, error) {
+	t, err := r.next()
+	if err != nil {
+		return UNDEF, err
+	}
+	if tmp := []rune(string(t)); strings.ContainsAny(runeToString(tmp[0]), "-0123456789") {
+		i, e := strconv.Atoi(string(t))
+		if e != nil {
+			return Symbol(t), nil // when ParseInt fails, this works
+		}
+		return Int(i), nil
+	} else if tmp[0] == '"' {
+		s, err := StringLiteral(string(tmp[1 : len(tmp)-1])).unescape()
+		if err != nil {
+			return UNDEF, err
+		}
+		return s, nil
+	} else if tmp[0] == ':' {
+		return Keyword(tmp[1:]), nil
+	} else if t == "true" {
+		return Bool(true), nil
+	} else if t == "false" {
+		return Bool(false), nil
+	} else if t == "nil" {
+		return NIL, nil
+	}
+	return Symbol(t), nil
+}
+
+// readHashMap makes a HashMap literal. Unlike assoc, a duplicated key is an error.
+func readHashMap(l []SExp) (SExp, error) {
+	if len(l)%2 != 0 {
+		return UNDEF, errors.New("odd number of forms in HashMap literal")
+	}
+	hm := make(HashMap, len(l)/2)
+	for i := 0; i < len(l); i += 2 {
+		if !isHashKey(l[i]) {
+			return UNDEF, errors.New("invalid HashMap key " + l[i].toString())
+		}
+		if _, ok := hm[l[i]]; ok {
+			return UNDEF, errors.New("duplicate key " + l[i].toString() + " in HashMap literal")
+		}
+		hm[l[i]] = l[i+1]
+	}
+	return hm, nil
+}
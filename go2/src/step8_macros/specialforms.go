@@ -0,0 +1,293 @@
+package main
+
+import "errors"
+
+const IF = "if"
+const DEF = "def!"
+const DEFMACRO = "defmacro!"
+const LET = "let*"
+const FN = "fn*"
+const DO = "do"
+const QUOTESF = "quote"
+const QUASIQUOTESF = "quasiquote"
+const MACROEXPAND = "macroexpand"
+
+var specialFormMap = map[string]struct{}{
+	IF: struct{}{}, DEF: struct{}{}, DEFMACRO: struct{}{},
+	LET: struct{}{}, FN: struct{}{}, QUOTESF: struct{}{},
+	QUASIQUOTESF: struct{}{}, MACROEXPAND: struct{}{},
+	DO: struct{}{},
+}
+
+func isSpecialForm(s SExp) (string, bool) {
+	switch s.(type) {
+	case Symbol:
+		_, ok := specialFormMap[string(s.(Symbol))]
+		return string(s.(Symbol)), ok
+	default:
+		return "", false
+	}
+}
+
+// evalIf returns the branch to be evaluated in tail position
+func evalIf(env Env, l List) (SExp, Env, error) {
+	if len(l) != 2 && len(l) != 3 {
+		return UNDEF, env, errors.New("'(if COND THEN ELSE)'")
+	}
+	cond := true
+	c, err := l[0].eval(env)
+	if err != nil {
+		return UNDEF, env, err
+	}
+	switch c := c.(type) {
+	case NilType:
+		cond = false
+	case Bool:
+		cond = bool(c)
+	}
+	if cond {
+		return l[1], env, nil
+	} else if len(l) >= 3 {
+		return l[2], env, nil
+	} else {
+		return NIL, env, nil
+	}
+}
+
+func evalDef(env Env, l List) (SExp, error) {
+	if len(l) != 2 {
+		return UNDEF, errors.New("'(def! SYMBOL EXP)'")
+	}
+	switch l[0].(type) {
+	case Symbol:
+		s := l[0].(Symbol)
+		v, err := l[1].eval(env)
+		if err != nil {
+			return UNDEF, err
+		}
+		env.set(s, v)
+		return v, nil
+	default:
+		return UNDEF, errors.New("'(def! SYMBOL EXP)'")
+	}
+}
+
+func evalDefMacro(env Env, l List) (SExp, error) {
+	if len(l) != 2 {
+		return UNDEF, errors.New("'(defmacro! SYMBOL (fn* ...))'")
+	}
+	v, err := evalDef(env, l)
+	if err != nil {
+		return UNDEF, err
+	}
+	c, ok := v.(Closure)
+	if !ok {
+		return UNDEF, errors.New("'(defmacro! SYMBOL (fn* ...))'")
+	}
+	c.isMacro = true
+	env.set(l[0].(Symbol), c)
+	return c, nil
+}
+
+// evalLet binds the variables and returns the body with the new environment
+func evalLet(env Env, l List) (SExp, Env, error) {
+	if len(l) != 2 {
+		return UNDEF, env, errors.New("'(let* (SYMBOL EXP ...) EXP)'")
+	}
+	var vars List
+	switch v := l[0].(type) {
+	case List:
+		vars = v
+	case Vector:
+		vars = v.toList()
+	default:
+		return UNDEF, env, errors.New("Syntax error: let*")
+	}
+	if len(vars)%2 != 0 {
+		return UNDEF, env, errors.New("Syntax Error: let*'s bind")
+	}
+	tmpEnv := makeNewEnv(env)
+	for i := 0; i < len(vars); i += 2 {
+		if err := evalLetBindOne(tmpEnv, vars[i:i+2]); err != nil {
+			return UNDEF, env, err
+		}
+	}
+	return l[1], tmpEnv, nil
+}
+
+func evalLetBindOne(env Env, l List) error {
+	switch l[0].(type) {
+	case Symbol:
+		vname := l[0].(Symbol)
+		value, err := l[1].eval(env)
+		if err != nil {
+			return err
+		}
+		env.set(vname, value)
+		return nil
+	default:
+		return errors.New("Syntax error: let*'s bind")
+	}
+}
+
+// evalDo evaluates all but the last expression, which is left for the caller
+func evalDo(env Env, l List) (SExp, Env, error) {
+	if len(l) == 0 {
+		return NIL, env, nil
+	}
+	for _, s := range l[:len(l)-1] {
+		if _, err := s.eval(env); err != nil {
+			return UNDEF, env, err
+		}
+	}
+	return l[len(l)-1], env, nil
+}
+
+func evalFn(env Env, l List) (SExp, error) {
+	if len(l) != 2 {
+		return UNDEF, errors.New("'(fn* (SYMBOL ...) EXP)'")
+	}
+	var params []SExp
+	switch l[0].(type) {
+	case List:
+		params = l[0].(List)
+	case Vector:
+		params = l[0].(Vector)
+	default:
+		return UNDEF, errors.New("fn*'s parameters should be List or Vector ... but got " + l[0].toString())
+	}
+	cparams := make([]Symbol, len(params))
+	for i, p := range params {
+		switch p.(type) {
+		case Symbol:
+			cparams[i] = p.(Symbol)
+		default:
+			return UNDEF, errors.New("fn* param should be SYMBOL ... but got " + p.toString())
+		}
+		if string(cparams[i]) == "&" && i != len(params)-2 {
+			return UNDEF, errors.New("fn*'s '&' should be followed by exactly one SYMBOL")
+		}
+	}
+	return Closure{
+		env:    env,
+		params: cparams,
+		body:   l[1],
+	}, nil
+}
+
+func evalQuote(env Env, l List) (SExp, error) {
+	if len(l) != 1 {
+		return UNDEF, errors.New("'(quote EXP)'")
+	}
+	return l[0], nil
+}
+
+func evalQuasiquote(env Env, l List) (SExp, Env, error) {
+	if len(l) != 1 {
+		return UNDEF, env, errors.New("'(quasiquote EXP)'")
+	}
+	qq, err := quasiquote(l[0])
+	return qq, env, err
+}
+
+func evalMacroexpand(env Env, l List) (SExp, error) {
+	if len(l) != 1 {
+		return UNDEF, errors.New("'(macroexpand EXP)'")
+	}
+	return macroexpand(l[0], env)
+}
+
+// isMacroCall returns the macro if s is a call of a macro defined in env
+func isMacroCall(s SExp, env Env) (Closure, bool) {
+	l, ok := s.(List)
+	if !ok || len(l) == 0 {
+		return Closure{}, false
+	}
+	sym, ok := l[0].(Symbol)
+	if !ok {
+		return Closure{}, false
+	}
+	v, ok := env.get(sym)
+	if !ok {
+		return Closure{}, false
+	}
+	c, ok := v.(Closure)
+	if !ok || !c.isMacro {
+		return Closure{}, false
+	}
+	return c, true
+}
+
+// macroexpand applies macros to the unevaluated arguments until s isn't a macro call
+func macroexpand(s SExp, env Env) (SExp, error) {
+	for {
+		c, ok := isMacroCall(s, env)
+		if !ok {
+			return s, nil
+		}
+		var err error
+		s, err = c.apply(s.(List)[1:])
+		if err != nil {
+			return UNDEF, err
+		}
+	}
+}
+
+// quasiquote rewrites `x into an expression built with cons and concat
+func quasiquote(s SExp) (SExp, error) {
+	if !isPair(s) {
+		return List{Symbol(QUOTESF), s}, nil
+	}
+	l := toList(s)
+	if isSymbol(l[0], "unquote") {
+		if len(l) != 2 {
+			return UNDEF, errors.New("'(unquote EXP)'")
+		}
+		return l[1], nil
+	}
+	rest, err := quasiquote(l[1:])
+	if err != nil {
+		return UNDEF, err
+	}
+	if isPair(l[0]) {
+		if l0 := toList(l[0]); isSymbol(l0[0], "splice-unquote") {
+			if len(l0) != 2 {
+				return UNDEF, errors.New("'(splice-unquote EXP)'")
+			}
+			return List{Symbol("concat"), l0[1], rest}, nil
+		}
+	}
+	first, err := quasiquote(l[0])
+	if err != nil {
+		return UNDEF, err
+	}
+	return List{Symbol("cons"), first, rest}, nil
+}
+
+func isPair(s SExp) bool {
+	switch s := s.(type) {
+	case List:
+		return len(s) > 0
+	case Vector:
+		return len(s) > 0
+	}
+	return false
+}
+
+func isSymbol(s SExp, name string) bool {
+	switch s := s.(type) {
+	case Symbol:
+		return string(s) == name
+	}
+	return false
+}
+
+func toList(s SExp) List {
+	switch s := s.(type) {
+	case List:
+		return s
+	case Vector:
+		return s.toList()
+	}
+	return nil
+}
@@ -0,0 +1,598 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"sort"
+)
+
+// SExp : a S SExpression
+type SExp interface {
+	toString() string
+	printStr(isReadable bool) string
+	eval(Env) (SExp, error)
+	copy() SExp
+	isSame(SExp) bool
+}
+
+// Undefined : Undefined symbol. When an error occurred, reader returns UNDEF and err
+type Undefined int
+
+func (u Undefined) toString() string           { return "*Undefined*" }
+func (u Undefined) printStr(_ bool) string     { return u.toString() }
+func (u Undefined) eval(env Env) (SExp, error) { return u, nil }
+func (u Undefined) copy() SExp                 { return u }
+func (u Undefined) isSame(s SExp) bool {
+	switch s.(type) {
+	case Undefined:
+		return true
+	}
+	return false
+}
+
+// UNDEF : Undef
+const UNDEF = Undefined(0)
+
+// NilType : the type of nil
+type NilType int
+
+func (n NilType) toString() string           { return "nil" }
+func (n NilType) printStr(_ bool) string     { return n.toString() }
+func (n NilType) eval(env Env) (SExp, error) { return n, nil }
+func (n NilType) copy() SExp                 { return n }
+func (n NilType) isSame(s SExp) bool {
+	switch s.(type) {
+	case NilType:
+		return true
+	}
+	return false
+}
+
+// NIL : Nil
+const NIL = NilType(0)
+
+// Bool : bool
+type Bool bool
+
+func (b Bool) toString() string           { return fmt.Sprint(b) }
+func (b Bool) printStr(_ bool) string     { return b.toString() }
+func (b Bool) eval(env Env) (SExp, error) { return b, nil }
+func (b Bool) copy() SExp                 { return b }
+func (b Bool) isSame(s SExp) bool {
+	switch s := s.(type) {
+	case Bool:
+		return b == s
+	}
+	return false
+}
+
+// Int : integer
+type Int int
+
+func (i Int) toString() string           { return fmt.Sprint(i) }
+func (i Int) printStr(_ bool) string     { return i.toString() }
+func (i Int) eval(env Env) (SExp, error) { return i, nil }
+func (i Int) copy() SExp                 { return i }
+func (i Int) isSame(s SExp) bool {
+	switch s := s.(type) {
+	case Int:
+		return i == s
+	}
+	return false
+}
+
+// Symbol : Symbol
+type Symbol string
+
+func (s Symbol) toString() string       { return string(s) }
+func (s Symbol) printStr(_ bool) string { return s.toString() }
+func (s Symbol) eval(env Env) (SExp, error) {
+	v, ok := env.get(s)
+	if ok {
+		return v, nil
+	}
+	return UNDEF, errors.New("can't find Symbol " + s.toString())
+}
+func (s Symbol) copy() SExp { return s }
+func (s Symbol) isSame(se SExp) bool {
+	switch t := se.(type) {
+	case Symbol:
+		return s == t
+	}
+	return false
+}
+
+// Keyword : Keyword
+type Keyword string
+
+func (k Keyword) toString() string           { return ":" + string(k) }
+func (k Keyword) printStr(_ bool) string     { return k.toString() }
+func (k Keyword) eval(env Env) (SExp, error) { return k, nil }
+func (k Keyword) copy() SExp                 { return k }
+func (k Keyword) isSame(s SExp) bool {
+	switch s := s.(type) {
+	case Keyword:
+		return s == k
+	}
+	return false
+}
+
+// StringLiteral : should be print with '"'
+type StringLiteral string
+
+func (s StringLiteral) toString() string {
+	return "\"" + string(s) + "\""
+}
+func (s StringLiteral) printStr(isReadable bool) string {
+	if isReadable {
+		return fmt.Sprintf("\"%s\"", s.escape())
+	}
+	return string(s)
+}
+func (s StringLiteral) eval(env Env) (SExp, error) { return s, nil }
+func (s StringLiteral) copy() SExp                 { return s }
+func (s StringLiteral) isSame(t SExp) bool {
+	switch t := t.(type) {
+	case StringLiteral:
+		return s == t
+	}
+	return false
+}
+
+func (s StringLiteral) escape() StringLiteral {
+	str := string(s)
+	ret := ""
+	for _, r := range str {
+		switch r {
+		case '\n':
+			ret += "\\n"
+		case '"':
+			ret += "\\\""
+		case '\\':
+			ret += "\\\\"
+		default:
+			ret += fmt.Sprintf("%c", r)
+		}
+	}
+	return StringLiteral(ret)
+}
+
+// unescape interprets the escape sequences of a string literal
+func (s StringLiteral) unescape() (StringLiteral, error) {
+	str := string(s)
+	ret := ""
+	bs := false
+	for _, r := range str {
+		if bs {
+			bs = false
+			switch r {
+			case 'n':
+				ret += "\n"
+			case '"':
+				ret += "\""
+			case '\\':
+				ret += "\\"
+			default:
+				return "", fmt.Errorf("unknown escape sequence '\\%c' in string literal", r)
+			}
+		} else if r == '\\' {
+			bs = true
+		} else {
+			ret += fmt.Sprintf("%c", r)
+		}
+	}
+	return StringLiteral(ret), nil
+}
+
+// List : e.g. (1 2 3)
+type List []SExp
+
+func (l List) toString() string {
+	return toStringSexpSlice("(", []SExp(l), ")", true)
+}
+func (l List) printStr(isReadable bool) string {
+	return toStringSexpSlice("(", []SExp(l), ")", isReadable)
+}
+
+func (l List) eval(env Env) (SExp, error) {
+	// special forms and closures in tail position don't call eval recursively,
+	// they set the next expression and environment and loop
+	for {
+		if len(l) == 0 {
+			return l, nil
+		}
+		expanded, err := macroexpand(l, env)
+		if err != nil {
+			return UNDEF, err
+		}
+		var ok bool
+		l, ok = expanded.(List)
+		if !ok {
+			return expanded.eval(env)
+		}
+		if len(l) == 0 {
+			return l, nil
+		}
+		if v, ok := isSpecialForm(l[0]); ok {
+			var next SExp
+			var err error
+			switch v {
+			case IF:
+				next, env, err = evalIf(env, l[1:])
+			case DEF:
+				return evalDef(env, l[1:])
+			case DEFMACRO:
+				return evalDefMacro(env, l[1:])
+			case LET:
+				next, env, err = evalLet(env, l[1:])
+			case DO:
+				next, env, err = evalDo(env, l[1:])
+			case FN:
+				return evalFn(env, l[1:])
+			case QUOTESF:
+				return evalQuote(env, l[1:])
+			case QUASIQUOTESF:
+				next, env, err = evalQuasiquote(env, l[1:])
+			case MACROEXPAND:
+				return evalMacroexpand(env, l[1:])
+			default:
+				return UNDEF, errors.New("unknown special form " + v)
+			}
+			if err != nil {
+				return UNDEF, err
+			}
+			if next != nil {
+				if nl, ok := next.(List); ok {
+					l = nl
+					continue
+				}
+				return next.eval(env)
+			}
+		}
+		c, err := l[0].eval(env)
+		if err != nil {
+			return UNDEF, err
+		}
+		args := make(List, len(l)-1)
+		for i, elem := range l[1:] {
+			args[i], err = elem.eval(env)
+			if err != nil {
+				return UNDEF, err
+			}
+		}
+		switch c := c.(type) {
+		case CoreFunc:
+			return c.apply(args, env)
+		case Closure:
+			env, err = c.bind(args)
+			if err != nil {
+				return UNDEF, err
+			}
+			if nl, ok := c.body.(List); ok {
+				l = nl
+				continue
+			}
+			return c.body.eval(env)
+		}
+		return UNDEF, errors.New("can't apply " + l.toString())
+	}
+}
+
+func (l List) copy() SExp {
+	return l
+}
+
+func (l List) isSame(s SExp) bool {
+	switch s := s.(type) {
+	case List:
+		if len(l) != len(s) {
+			return false
+		}
+		for i := 0; i < len(l); i++ {
+			if !l[i].isSame(s[i]) {
+				return false
+			}
+		}
+		return true
+	case Vector:
+		return s.toList().isSame(l)
+	}
+	return false
+}
+
+// Vector : e.g. [1 2 3]
+type Vector []SExp
+
+func (v Vector) toString() string {
+	return toStringSexpSlice("[", []SExp(v), "]", true)
+}
+func (v Vector) printStr(isReadable bool) string {
+	return toStringSexpSlice("[", []SExp(v), "]", isReadable)
+}
+
+func (v Vector) eval(env Env) (SExp, error) {
+	ret := make(Vector, len(v))
+	for i, elem := range v {
+		var err error
+		ret[i], err = elem.eval(env)
+		if err != nil {
+			return UNDEF, err
+		}
+	}
+	return ret, nil
+}
+
+func (v Vector) copy() SExp {
+	return v
+}
+
+func (v Vector) isSame(s SExp) bool {
+	switch s := s.(type) {
+	case List:
+		return v.toList().isSame(s)
+	case Vector:
+		if len(v) != len(s) {
+			return false
+		}
+		for i := 0; i < len(v); i++ {
+			if !v[i].isSame(s[i]) {
+				return false
+			}
+		}
+		return true
+	}
+	return false
+}
+
+func (v Vector) toList() List {
+	return List(v)
+}
+
+// HashMap : {x 1, y 2}. Keys are Keyword, StringLiteral, Int or Symbol
+type HashMap map[SExp]SExp
+
+func (hm HashMap) toString() string {
+	return toStringSexpSlice("{", hm.flatten(), "}", true)
+}
+func (hm HashMap) printStr(isReadable bool) string {
+	return toStringSexpSlice("{", hm.flatten(), "}", isReadable)
+}
+
+func (hm HashMap) eval(env Env) (SExp, error) {
+	ret := make(HashMap, len(hm))
+	for key, val := range hm {
+		k, err := key.eval(env)
+		if err != nil {
+			return UNDEF, err
+		}
+		if !isHashKey(k) {
+			return UNDEF, errors.New("invalid HashMap key " + k.toString())
+		}
+		ret[k], err = val.eval(env)
+		if err != nil {
+			return UNDEF, err
+		}
+	}
+	return ret, nil
+}
+
+func (hm HashMap) copy() SExp {
+	t := make(HashMap, len(hm))
+	for key, val := range hm {
+		t[key] = val.copy()
+	}
+	return t
+}
+
+func (hm HashMap) isSame(s SExp) bool {
+	switch s := s.(type) {
+	case HashMap:
+		if len(hm) != len(s) {
+			return false
+		}
+		for key, val := range hm {
+			v, ok := s[key]
+			if !ok || !val.isSame(v) {
+				return false
+			}
+		}
+		return true
+	}
+	return false
+}
+
+// makeHashMap makes a HashMap from alternating keys and values. A later key overrides an earlier one.
+func makeHashMap(kvs []SExp) (HashMap, error) {
+	return HashMap{}.assoc(kvs)
+}
+
+// assoc returns a new HashMap with the key-value pairs in kvs added
+func (hm HashMap) assoc(kvs []SExp) (HashMap, error) {
+	if len(kvs)%2 != 0 {
+		return nil, errors.New("odd number of arguments for a HashMap")
+	}
+	ret := make(HashMap, len(hm)+len(kvs)/2)
+	for key, val := range hm {
+		ret[key] = val
+	}
+	for i := 0; i < len(kvs); i += 2 {
+		if !isHashKey(kvs[i]) {
+			return nil, errors.New("invalid HashMap key " + kvs[i].toString())
+		}
+		ret[kvs[i]] = kvs[i+1]
+	}
+	return ret, nil
+}
+
+// dissoc returns a new HashMap without keys
+func (hm HashMap) dissoc(keys []SExp) HashMap {
+	ret := make(HashMap, len(hm))
+	for key, val := range hm {
+		ret[key] = val
+	}
+	for _, key := range keys {
+		if isHashKey(key) {
+			delete(ret, key)
+		}
+	}
+	return ret
+}
+
+// get returns the value for key
+func (hm HashMap) get(key SExp) (SExp, bool) {
+	if !isHashKey(key) {
+		return UNDEF, false
+	}
+	v, ok := hm[key]
+	return v, ok
+}
+
+// keys returns the keys in the order they are printed
+func (hm HashMap) keys() []SExp {
+	ret := make([]SExp, 0, len(hm))
+	for key := range hm {
+		ret = append(ret, key)
+	}
+	sort.Slice(ret, func(i, j int) bool { return lessHashKey(ret[i], ret[j]) })
+	return ret
+}
+
+func (hm HashMap) flatten() []SExp {
+	ret := make([]SExp, 0, 2*len(hm))
+	for _, key := range hm.keys() {
+		ret = append(ret, key, hm[key])
+	}
+	return ret
+}
+
+func isHashKey(s SExp) bool {
+	switch s.(type) {
+	case Keyword, StringLiteral, Int, Symbol:
+		return true
+	}
+	return false
+}
+
+// lessHashKey orders keys by type (Int, StringLiteral, Keyword, Symbol), then by value
+func lessHashKey(a, b SExp) bool {
+	rank := func(s SExp) int {
+		switch s.(type) {
+		case Int:
+			return 0
+		case StringLiteral:
+			return 1
+		case Keyword:
+			return 2
+		}
+		return 3
+	}
+	if rank(a) != rank(b) {
+		return rank(a) < rank(b)
+	}
+	if x, ok := a.(Int); ok {
+		return x < b.(Int)
+	}
+	return a.printStr(false) < b.printStr(false)
+}
+
+// CoreFunc : function
+type CoreFunc func(args List, env Env) (SExp, error)
+
+func (c CoreFunc) toString() string           { return "*CoreFunc*" }
+func (c CoreFunc) printStr(_ bool) string     { return "*CoreFunc*" }
+func (c CoreFunc) eval(env Env) (SExp, error) { return c, nil }
+func (c CoreFunc) copy() SExp                 { return c }
+func (c CoreFunc) isSame(s SExp) bool         { return false } // Function isn't comparable
+
+func (c CoreFunc) apply(args List, env Env) (SExp, error) { return c(args, env) }
+
+// Closure : environment + arg List + body
+type Closure struct {
+	env     Env
+	params  []Symbol
+	body    SExp
+	isMacro bool
+}
+
+func (c Closure) toString() string           { return "*Closure*" }
+func (c Closure) printStr(_ bool) string     { return "*Closure*" }
+func (c Closure) eval(env Env) (SExp, error) { return c, nil }
+func (c Closure) isSame(s SExp) bool         { return false } // Closure isn't comparable
+func (c Closure) copy() SExp {
+	return c
+}
+func (c Closure) apply(args List) (SExp, error) {
+	env, err := c.bind(args)
+	if err != nil {
+		return UNDEF, err
+	}
+	return c.body.eval(env)
+}
+
+// bind makes the environment in which the body is evaluated.
+// evalFn has checked that '&' is followed by exactly one parameter.
+func (c Closure) bind(args List) (Env, error) {
+	n := len(c.params)
+	variadic := n >= 2 && c.params[n-2] == "&"
+	if variadic {
+		n -= 2
+	}
+	if len(args) < n || (!variadic && len(args) > n) {
+		if variadic {
+			return c.env, fmt.Errorf("fn* takes at least %d arguments, but got %d", n, len(args))
+		}
+		return c.env, fmt.Errorf("fn* takes %d arguments, but got %d", n, len(args))
+	}
+	ne := makeNewEnv(c.env)
+	for i, p := range c.params[:n] {
+		ne.set(p, args[i])
+	}
+	if variadic {
+		ne.set(c.params[n+1], append(List{}, args[n:]...))
+	}
+	return ne, nil
+}
+
+func applyFunc(f SExp, args List, env Env) (SExp, error) {
+	switch f := f.(type) {
+	case CoreFunc:
+		return f.apply(args, env)
+	case Closure:
+		return f.apply(args)
+	}
+	return UNDEF, errors.New("can't apply " + f.toString())
+}
+
+func toStringSexpSlice(ls string, sexps []SExp, rs string, isReadable bool) string {
+	t := make([]byte, 0, 10)
+	t = append(t, ls...)
+	for i, v := range sexps {
+		t = append(t, v.printStr(isReadable)...)
+		if i != len(sexps)-1 {
+			t = append(t, " "...)
+		}
+	}
+	t = append(t, rs...)
+	return string(t)
+}
+
+// Atom : atom. *Atom is shared by reference so reset! and swap! are visible to every holder
+type Atom struct {
+	ref SExp
+}
+
+func (a *Atom) toString() string {
+	return "(atom " + a.ref.toString() + ")"
+}
+func (a *Atom) printStr(b bool) string {
+	return "(atom " + a.ref.printStr(b) + ")"
+}
+func (a *Atom) eval(env Env) (SExp, error) { return a, nil }
+func (a *Atom) isSame(s SExp) bool {
+	switch s := s.(type) {
+	case *Atom:
+		return a == s
+	}
+	return false
+}
+func (a *Atom) copy() SExp { return a }
@@ -0,0 +1,675 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"strings"
+)
+
+type envInternal map[Symbol]SExp
+
+func makeEnvInternal() envInternal { return make(envInternal) }
+
+// Env : Environment. Env is shared by reference, so a closure sees definitions made after it was created
+type Env struct {
+	env     envInternal
+	nextEnv *Env
+}
+
+func (e Env) set(sym Symbol, sexp SExp) {
+	e.env[sym] = sexp
+}
+
+func (e Env) get(sym Symbol) (SExp, bool) {
+	v, ok := e.env[sym]
+	if ok || e.nextEnv == nil {
+		return v, ok
+	}
+	return e.nextEnv.get(sym)
+}
+
+func (e Env) del(sym Symbol) {
+	delete(e.env, sym)
+}
+
+func makeNewEnv(e Env) Env {
+	return Env{
+		env:     make(envInternal),
+		nextEnv: &e,
+	}
+}
+
+var replEnv Env
+
+func init() {
+	replEnv = Env{
+		env:     makeEnvInternal(),
+		nextEnv: nil,
+	}
+	plus := CoreFunc(
+		func(args List, _ Env) (SExp, error) {
+			s := 0
+			for _, v := range args {
+				switch v.(type) {
+				case Int:
+					s += int(v.(Int))
+				default:
+					return UNDEF, errors.New("invalid +'s argument")
+				}
+			}
+			return Int(s), nil
+		})
+	minus := CoreFunc(
+		func(args List, _ Env) (SExp, error) {
+			if len(args) == 0 {
+				return UNDEF, errors.New("'(- INT INT ...)'")
+			}
+			if _, ok := args[0].(Int); !ok {
+				return UNDEF, errors.New("invalid -'s argument")
+			}
+			s := int(args[0].(Int))
+			for _, v := range args[1:] {
+				switch v.(type) {
+				case Int:
+					s -= int(v.(Int))
+				default:
+					return UNDEF, errors.New("invalid -'s argument")
+				}
+			}
+			return Int(s), nil
+		})
+	times := CoreFunc(
+		func(args List, _ Env) (SExp, error) {
+			s := 1
+			for _, v := range args {
+				switch v.(type) {
+				case Int:
+					s *= int(v.(Int))
+				default:
+					return UNDEF, errors.New("invalid *'s argument")
+				}
+			}
+			return Int(s), nil
+		})
+	div := CoreFunc(
+		func(args List, _ Env) (SExp, error) {
+			if len(args) == 0 {
+				return UNDEF, errors.New("'(/ INT INT ...)'")
+			}
+			if _, ok := args[0].(Int); !ok {
+				return UNDEF, errors.New("invalid /'s argument")
+			}
+			s := int(args[0].(Int))
+			for _, v := range args[1:] {
+				switch v.(type) {
+				case Int:
+					if v.(Int) == 0 {
+						return UNDEF, errors.New("division by zero")
+					}
+					s /= int(v.(Int))
+				default:
+					return UNDEF, errors.New("invalid /'s argument")
+				}
+			}
+			return Int(s), nil
+		})
+	cmp := func(f func(x, y int) bool) CoreFunc {
+		return CoreFunc(func(args List, _ Env) (SExp, error) {
+			if len(args) < 2 {
+				return UNDEF, errors.New("few arguments for <,<=,>,>=")
+			}
+			switch x := args[0].(type) {
+			case Int:
+				switch y := args[1].(type) {
+				case Int:
+					return Bool(f(int(x), int(y))), nil
+				}
+			}
+			return UNDEF, errors.New("arguments for '<' should be Int")
+		})
+	}
+	lt := cmp(func(x, y int) bool { return x < y })
+	le := cmp(func(x, y int) bool { return x <= y })
+	gt := cmp(func(x, y int) bool { return x > y })
+	ge := cmp(func(x, y int) bool { return x >= y })
+	eq := CoreFunc(func(args List, _ Env) (SExp, error) {
+		if len(args) < 2 {
+			return UNDEF, errors.New("few arguments for =")
+		}
+		return Bool(args[0].isSame(args[1])), nil
+	})
+	list := CoreFunc(
+		func(args List, _ Env) (SExp, error) {
+			return args, nil
+		})
+	listq := CoreFunc(
+		func(args List, _ Env) (SExp, error) {
+			if len(args) != 1 {
+				return UNDEF, errors.New("type predicates take 1 argument")
+			}
+			switch args[0].(type) {
+			case List:
+				return Bool(true), nil
+			default:
+				return Bool(false), nil
+			}
+		})
+	emptyq := CoreFunc(
+		func(args List, _ Env) (SExp, error) {
+			if len(args) != 1 {
+				return UNDEF, errors.New("'(empty? LIST)'")
+			}
+			switch args[0].(type) {
+			case List:
+				return Bool(len(args[0].(List)) == 0), nil
+			case Vector:
+				return Bool(len(args[0].(Vector)) == 0), nil
+			case HashMap:
+				return Bool(len(args[0].(HashMap)) == 0), nil
+			default:
+				return Bool(false), nil
+			}
+		})
+	count := CoreFunc(
+		func(args List, _ Env) (SExp, error) {
+			if len(args) != 1 {
+				return UNDEF, errors.New("'(count LIST)'")
+			}
+			switch args[0].(type) {
+			case List:
+				return Int(len(args[0].(List))), nil
+			case Vector:
+				return Int(len(args[0].(Vector))), nil
+			case HashMap:
+				return Int(len(args[0].(HashMap))), nil
+			default:
+				return Int(0), nil
+			}
+		})
+	not := CoreFunc(func(args List, _ Env) (SExp, error) {
+		if len(args) != 1 {
+			return UNDEF, errors.New("'(not EXP)'")
+		}
+		b := true
+		switch args[0].(type) {
+		case NilType:
+			b = false
+		case Bool:
+			b = bool(args[0].(Bool))
+		case List:
+			list := args[0].(List)
+			b = len(list) == 0
+		}
+		return Bool(!b), nil
+	})
+	prstr := CoreFunc(
+		func(args List, _ Env) (SExp, error) {
+			s := printStrList(args, true, " ")
+			return StringLiteral(s), nil
+		})
+	prn := CoreFunc(
+		func(args List, _ Env) (SExp, error) {
+			fmt.Println(printStrList(args, true, " "))
+			return NIL, nil
+		})
+	str := CoreFunc(
+		func(args List, _ Env) (SExp, error) {
+			s := ""
+			for _, a := range args {
+				s += a.printStr(false)
+			}
+			return StringLiteral(s), nil
+		})
+	printlnCF := CoreFunc(
+		func(args List, _ Env) (SExp, error) {
+			fmt.Println(printStrList(args, false, " "))
+			return NIL, nil
+		})
+	readString := CoreFunc(
+		func(args List, _ Env) (SExp, error) {
+			if len(args) != 1 {
+				return UNDEF, errors.New("'(read-string STRING)'")
+			}
+			s, ok := args[0].(StringLiteral)
+			if !ok {
+				return NIL, errors.New("invalid read-string arg")
+			}
+			r := initReader(string(s))
+			sexp, err := r.readForm()
+			if err == nil && sexp == UNDEF { // nothing to read
+				return NIL, nil
+			}
+			return sexp, err
+		})
+	evalCore := CoreFunc(
+		func(args List, env Env) (SExp, error) {
+			if len(args) != 1 {
+				return UNDEF, errors.New("'(eval EXP)'")
+			}
+			return args[0].eval(env)
+		})
+	slurp := CoreFunc(
+		func(args List, env Env) (SExp, error) {
+			if len(args) != 1 {
+				return UNDEF, errors.New("'(slurp FILENAME)'")
+			}
+			fn, ok := args[0].(StringLiteral)
+			if !ok {
+				return NIL, errors.New("invalid slurp arg")
+			}
+			fp, err := os.Open(string(fn))
+			if err != nil {
+				return NIL, err
+			}
+			defer fp.Close()
+			contents, err := ioutil.ReadAll(fp)
+			if err != nil {
+				return NIL, err
+			}
+			return StringLiteral(contents), nil
+		})
+	loadFile := CoreFunc(
+		func(args List, env Env) (SExp, error) {
+			if len(args) != 1 {
+				return UNDEF, errors.New("'(load-file FILENAME)'")
+			}
+			s, err := slurp.apply(args, env)
+			if err != nil {
+				return NIL, err
+			}
+			str, ok := s.(StringLiteral)
+			if !ok {
+				return NIL, errors.New("!")
+			}
+			r := initReader(string(str))
+			var val SExp = NIL
+			for !r.isReachedEND {
+				sexp, err := r.readForm()
+				if err != nil {
+					return NIL, err
+				}
+				buf, err := sexp.eval(env)
+				if err != nil {
+					return val, err
+				}
+				if buf != UNDEF {
+					val = buf
+				}
+			}
+			return val, nil
+		})
+	atom := CoreFunc(
+		func(args List, env Env) (SExp, error) {
+			if len(args) != 1 {
+				return UNDEF, errors.New("'(atom EXP)'")
+			}
+			return &Atom{
+				ref: args[0],
+			}, nil
+		})
+	atomq := CoreFunc(
+		func(args List, env Env) (SExp, error) {
+			if len(args) != 1 {
+				return UNDEF, errors.New("type predicates take 1 argument")
+			}
+			switch args[0].(type) {
+			case *Atom:
+				return Bool(true), nil
+			}
+			return Bool(false), nil
+		})
+	deref := CoreFunc(
+		func(args List, env Env) (SExp, error) {
+			if len(args) != 1 {
+				return UNDEF, errors.New("'(deref ATOM)'")
+			}
+			switch a := args[0].(type) {
+			case *Atom:
+				return a.ref, nil
+			}
+			return NIL, nil
+		})
+	cons := CoreFunc(
+		func(args List, env Env) (SExp, error) {
+			if len(args) != 2 {
+				return UNDEF, errors.New("'(cons EXP LIST)'")
+			}
+			var tail []SExp
+			switch l := args[1].(type) {
+			case List:
+				tail = l
+			case Vector:
+				tail = l
+			case NilType:
+			default:
+				return UNDEF, errors.New("cons's second argument should be List or Vector")
+			}
+			ret := make(List, 0, len(tail)+1)
+			ret = append(ret, args[0])
+			return append(ret, tail...), nil
+		})
+	concat := CoreFunc(
+		func(args List, env Env) (SExp, error) {
+			ret := make(List, 0)
+			for _, a := range args {
+				switch l := a.(type) {
+				case List:
+					ret = append(ret, l...)
+				case Vector:
+					ret = append(ret, l...)
+				case NilType:
+				default:
+					return UNDEF, errors.New("concat's arguments should be List or Vector")
+				}
+			}
+			return ret, nil
+		})
+	nth := CoreFunc(
+		func(args List, env Env) (SExp, error) {
+			if len(args) != 2 {
+				return UNDEF, errors.New("'(nth LIST INDEX)'")
+			}
+			i, ok := args[1].(Int)
+			if !ok {
+				return UNDEF, errors.New("nth's index should be Int")
+			}
+			l := toList(args[0])
+			if int(i) < 0 || int(i) >= len(l) {
+				return UNDEF, errors.New("nth: index out of range")
+			}
+			return l[i], nil
+		})
+	first := CoreFunc(
+		func(args List, env Env) (SExp, error) {
+			if len(args) != 1 {
+				return UNDEF, errors.New("'(first LIST)'")
+			}
+			l := toList(args[0])
+			if len(l) == 0 {
+				return NIL, nil
+			}
+			return l[0], nil
+		})
+	rest := CoreFunc(
+		func(args List, env Env) (SExp, error) {
+			if len(args) != 1 {
+				return UNDEF, errors.New("'(rest LIST)'")
+			}
+			l := toList(args[0])
+			if len(l) == 0 {
+				return List{}, nil
+			}
+			return append(List{}, l[1:]...), nil
+		})
+	throw := CoreFunc(
+		func(args List, env Env) (SExp, error) {
+			if len(args) != 1 {
+				return UNDEF, errors.New("'(throw EXP)'")
+			}
+			return UNDEF, Exception{value: args[0]}
+		})
+	typeq := func(f func(s SExp) bool) CoreFunc {
+		return CoreFunc(func(args List, _ Env) (SExp, error) {
+			if len(args) != 1 {
+				return UNDEF, errors.New("type predicates take 1 argument")
+			}
+			return Bool(f(args[0])), nil
+		})
+	}
+	nilq := typeq(func(s SExp) bool { return s.isSame(NIL) })
+	trueq := typeq(func(s SExp) bool { return s.isSame(Bool(true)) })
+	falseq := typeq(func(s SExp) bool { return s.isSame(Bool(false)) })
+	symbolq := typeq(func(s SExp) bool { _, ok := s.(Symbol); return ok })
+	keywordq := typeq(func(s SExp) bool { _, ok := s.(Keyword); return ok })
+	vectorq := typeq(func(s SExp) bool { _, ok := s.(Vector); return ok })
+	mapq := typeq(func(s SExp) bool { _, ok := s.(HashMap); return ok })
+	sequentialq := typeq(func(s SExp) bool {
+		switch s.(type) {
+		case List, Vector:
+			return true
+		}
+		return false
+	})
+	symbol := CoreFunc(
+		func(args List, env Env) (SExp, error) {
+			if len(args) != 1 {
+				return UNDEF, errors.New("'(symbol STRING)'")
+			}
+			s, ok := args[0].(StringLiteral)
+			if !ok {
+				return UNDEF, errors.New("symbol's argument should be String")
+			}
+			return Symbol(s), nil
+		})
+	keyword := CoreFunc(
+		func(args List, env Env) (SExp, error) {
+			if len(args) != 1 {
+				return UNDEF, errors.New("'(keyword STRING)'")
+			}
+			switch s := args[0].(type) {
+			case StringLiteral:
+				return Keyword(s), nil
+			case Keyword:
+				return s, nil
+			}
+			return UNDEF, errors.New("keyword's argument should be String")
+		})
+	vector := CoreFunc(
+		func(args List, env Env) (SExp, error) {
+			return Vector(append(List{}, args...)), nil
+		})
+	apply := CoreFunc(
+		func(args List, env Env) (SExp, error) {
+			if len(args) < 2 {
+				return UNDEF, errors.New("'(apply FUNC ARGS... LIST)'")
+			}
+			last := args[len(args)-1]
+			switch last.(type) {
+			case List, Vector:
+			default:
+				return UNDEF, errors.New("apply's last argument should be List or Vector")
+			}
+			fargs := append(List{}, args[1:len(args)-1]...)
+			fargs = append(fargs, toList(last)...)
+			return applyFunc(args[0], fargs, env)
+		})
+	mapCF := CoreFunc(
+		func(args List, env Env) (SExp, error) {
+			if len(args) != 2 {
+				return UNDEF, errors.New("'(map FUNC LIST)'")
+			}
+			l := toList(args[1])
+			ret := make(List, len(l))
+			for i, v := range l {
+				var err error
+				ret[i], err = applyFunc(args[0], List{v}, env)
+				if err != nil {
+					return UNDEF, err
+				}
+			}
+			return ret, nil
+		})
+	hashMap := CoreFunc(
+		func(args List, env Env) (SExp, error) {
+			hm, err := makeHashMap(args)
+			if err != nil {
+				return UNDEF, err
+			}
+			return hm, nil
+		})
+	assoc := CoreFunc(
+		func(args List, env Env) (SExp, error) {
+			if len(args) == 0 {
+				return UNDEF, errors.New("'(assoc MAP KEY VALUE ...)'")
+			}
+			hm, ok := args[0].(HashMap)
+			if !ok {
+				return UNDEF, errors.New("assoc's first argument should be HashMap")
+			}
+			ret, err := hm.assoc(args[1:])
+			if err != nil {
+				return UNDEF, err
+			}
+			return ret, nil
+		})
+	dissoc := CoreFunc(
+		func(args List, env Env) (SExp, error) {
+			if len(args) == 0 {
+				return UNDEF, errors.New("'(dissoc MAP KEY ...)'")
+			}
+			hm, ok := args[0].(HashMap)
+			if !ok {
+				return UNDEF, errors.New("dissoc's first argument should be HashMap")
+			}
+			return hm.dissoc(args[1:]), nil
+		})
+	get := CoreFunc(
+		func(args List, env Env) (SExp, error) {
+			if len(args) != 2 {
+				return UNDEF, errors.New("'(get MAP KEY)'")
+			}
+			switch hm := args[0].(type) {
+			case HashMap:
+				if v, ok := hm.get(args[1]); ok {
+					return v, nil
+				}
+				return NIL, nil
+			case NilType:
+				return NIL, nil
+			}
+			return UNDEF, errors.New("get's first argument should be HashMap")
+		})
+	containsq := CoreFunc(
+		func(args List, env Env) (SExp, error) {
+			if len(args) != 2 {
+				return UNDEF, errors.New("'(contains? MAP KEY)'")
+			}
+			switch hm := args[0].(type) {
+			case HashMap:
+				_, ok := hm.get(args[1])
+				return Bool(ok), nil
+			case NilType:
+				return Bool(false), nil
+			}
+			return UNDEF, errors.New("contains?'s first argument should be HashMap")
+		})
+	keys := CoreFunc(
+		func(args List, env Env) (SExp, error) {
+			if len(args) != 1 {
+				return UNDEF, errors.New("'(keys MAP)'")
+			}
+			hm, ok := args[0].(HashMap)
+			if !ok {
+				return UNDEF, errors.New("keys's argument should be HashMap")
+			}
+			return List(hm.keys()), nil
+		})
+	vals := CoreFunc(
+		func(args List, env Env) (SExp, error) {
+			if len(args) != 1 {
+				return UNDEF, errors.New("'(vals MAP)'")
+			}
+			hm, ok := args[0].(HashMap)
+			if !ok {
+				return UNDEF, errors.New("vals's argument should be HashMap")
+			}
+			ret := make(List, 0, len(hm))
+			for _, key := range hm.keys() {
+				ret = append(ret, hm[key])
+			}
+			return ret, nil
+		})
+	resetCF := CoreFunc(
+		func(args List, env Env) (SExp, error) {
+			if len(args) != 2 {
+				return UNDEF, errors.New("'(reset! ATOM EXP)'")
+			}
+			a, ok := args[0].(*Atom)
+			if !ok {
+				return UNDEF, errors.New("reset!'s first argument should be Atom")
+			}
+			a.ref = args[1]
+			return a.ref, nil
+		})
+	swap := CoreFunc(
+		func(args List, env Env) (SExp, error) {
+			if len(args) < 2 {
+				return UNDEF, errors.New("'(swap! ATOM FUNC ARGS...)'")
+			}
+			a, ok := args[0].(*Atom)
+			if !ok {
+				return UNDEF, errors.New("swap!'s first argument should be Atom")
+			}
+			fargs := append(List{a.ref}, args[2:]...)
+			v, err := applyFunc(args[1], fargs, env)
+			if err != nil {
+				return UNDEF, err
+			}
+			a.ref = v
+			return v, nil
+		})
+	replEnv.set(Symbol("+"), plus)
+	replEnv.set(Symbol("-"), minus)
+	replEnv.set(Symbol("*"), times)
+	replEnv.set(Symbol("/"), div)
+	replEnv.set(Symbol("<"), lt)
+	replEnv.set(Symbol("<="), le)
+	replEnv.set(Symbol(">"), gt)
+	replEnv.set(Symbol(">="), ge)
+	replEnv.set(Symbol("="), eq)
+	replEnv.set(Symbol("list"), list)
+	replEnv.set(Symbol("list?"), listq)
+	replEnv.set(Symbol("empty?"), emptyq)
+	replEnv.set(Symbol("count"), count)
+	replEnv.set(Symbol("not"), not)
+	replEnv.set(Symbol("prn"), prn)
+	replEnv.set(Symbol("str"), str)
+	replEnv.set(Symbol("pr-str"), prstr)
+	replEnv.set(Symbol("println"), printlnCF)
+	replEnv.set(Symbol("read-string"), readString)
+	replEnv.set(Symbol("eval"), evalCore)
+	replEnv.set(Symbol("slurp"), slurp)
+	replEnv.set(Symbol("load-file"), loadFile)
+	replEnv.set(Symbol("atom"), atom)
+	replEnv.set(Symbol("atom?"), atomq)
+	replEnv.set(Symbol("deref"), deref)
+	replEnv.set(Symbol("*ARGV*"), List{})
+	replEnv.set(Symbol("reset!"), resetCF)
+	replEnv.set(Symbol("swap!"), swap)
+	replEnv.set(Symbol("cons"), cons)
+	replEnv.set(Symbol("concat"), concat)
+	replEnv.set(Symbol("nth"), nth)
+	replEnv.set(Symbol("first"), first)
+	replEnv.set(Symbol("rest"), rest)
+	replEnv.set(Symbol("throw"), throw)
+	replEnv.set(Symbol("nil?"), nilq)
+	replEnv.set(Symbol("true?"), trueq)
+	replEnv.set(Symbol("false?"), falseq)
+	replEnv.set(Symbol("symbol?"), symbolq)
+	replEnv.set(Symbol("keyword?"), keywordq)
+	replEnv.set(Symbol("vector?"), vectorq)
+	replEnv.set(Symbol("map?"), mapq)
+	replEnv.set(Symbol("sequential?"), sequentialq)
+	replEnv.set(Symbol("symbol"), symbol)
+	replEnv.set(Symbol("keyword"), keyword)
+	replEnv.set(Symbol("vector"), vector)
+	replEnv.set(Symbol("apply"), apply)
+	replEnv.set(Symbol("map"), mapCF)
+	replEnv.set(Symbol("hash-map"), hashMap)
+	replEnv.set(Symbol("assoc"), assoc)
+	replEnv.set(Symbol("dissoc"), dissoc)
+	replEnv.set(Symbol("get"), get)
+	replEnv.set(Symbol("contains?"), containsq)
+	replEnv.set(Symbol("keys"), keys)
+	replEnv.set(Symbol("vals"), vals)
+}
+
+func printStrList(sexps List, isReadable bool, sep string) string {
+	s := make([]string, len(sexps))
+	for i, e := range sexps {
+		s[i] = e.printStr(isReadable)
+	}
+	return strings.Join(s, sep)
+}
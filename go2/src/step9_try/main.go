@@ -0,0 +1,89 @@
+package main
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+)
+
+var stdin = bufio.NewReader(os.Stdin)
+
+// readLine prints prompt and reads a line from stdin. It returns false at EOF.
+func readLine(prompt string) (string, bool) {
+	fmt.Print(prompt)
+	line, err := stdin.ReadString('\n')
+	if err == io.EOF && len(line) > 0 {
+		err = nil
+	}
+	if err != nil {
+		return "", false
+	}
+	return strings.TrimRight(line, "\r\n"), true
+}
+
+func read(s string) (SExp, error) {
+	r := initReader(s)
+	return r.readForm()
+}
+
+func eval(e SExp) (SExp, error) {
+	return e.eval(replEnv)
+}
+
+func print(e SExp) {
+	fmt.Println(e.printStr(true))
+}
+
+// prelude : functions and macros defined in mal itself
+var prelude = []string{
+	"(defmacro! cond (fn* (& xs) (if (> (count xs) 0) (list 'if (first xs) (if (> (count xs) 1) (nth xs 1) (throw \"odd number of forms to cond\")) (cons 'cond (rest (rest xs)))))))",
+	"(defmacro! or (fn* (& xs) (if (empty? xs) nil (if (= 1 (count xs)) (first xs) `(let* (or_FIXME ~(first xs)) (if or_FIXME or_FIXME (or ~@(rest xs))))))))",
+}
+
+func main() {
+	for _, s := range prelude {
+		sexp, err := read(s)
+		if err != nil {
+			panic(err)
+		}
+		if _, err := sexp.eval(replEnv); err != nil {
+			panic(err)
+		}
+	}
+
+	// called with mal script to load and eval
+	if len(os.Args) > 1 {
+		args := make(List, 0, len(os.Args)-2)
+		for _, a := range os.Args[2:] {
+			args = append(args, StringLiteral(a))
+		}
+		replEnv.set(Symbol("*ARGV*"), args)
+		loadFile, _ := replEnv.get(Symbol("load-file"))
+		if _, err := applyFunc(loadFile, List{StringLiteral(os.Args[1])}, replEnv); err != nil {
+			fmt.Println("Error: " + err.Error())
+			os.Exit(1)
+		}
+		os.Exit(0)
+	}
+
+	for {
+		line, ok := readLine("user> ")
+		if !ok {
+			break
+		}
+		s, err := read(line)
+		if err == nil && s == UNDEF { // UNDEF means empty input
+			continue
+		}
+		if err == nil {
+			s, err = eval(s)
+		}
+		if err != nil {
+			fmt.Println("Error: " + err.Error())
+		} else {
+			print(s)
+		}
+	}
+}
@@ -0,0 +1,35 @@
+package main
+
+import (
+	"fmt"
+	"os"
+
+	"mal"
+)
+
+func main() {
+	in := mal.NewInterpreter(os.Stdin, os.Stdout)
+
+	// called with mal script to load and eval
+	if len(os.Args) > 1 {
+		if err := in.Run(os.Args[1], os.Args[2:]); err != nil {
+			fmt.Println("Error: " + err.Error())
+			os.Exit(1)
+		}
+		os.Exit(0)
+	}
+
+	in.Eval(`(println (str "Mal [" *host-language* "]"))`)
+	for {
+		line, ok := in.ReadLine("user> ")
+		if !ok {
+			break
+		}
+		v, err := in.Eval(line)
+		if err != nil {
+			fmt.Println("Error: " + err.Error())
+		} else if v != mal.UNDEF { // UNDEF means empty input
+			fmt.Println(v.PrintStr(true))
+		}
+	}
+}